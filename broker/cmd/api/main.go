@@ -7,21 +7,30 @@ package main
 import (
 	"flag"
 	"log"
+	"net"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	_ "github.com/go-playground/validator/v10"
 
 	"github.com/melyouz/risala/broker/internal"
+	grpcserver "github.com/melyouz/risala/broker/internal/grpc/server"
 	"github.com/melyouz/risala/broker/internal/http/server"
+	"github.com/melyouz/risala/broker/internal/routing"
 	"github.com/melyouz/risala/broker/internal/sample"
 	"github.com/melyouz/risala/broker/internal/storage"
 )
 
+const deadLetterSweepInterval = time.Second
+
 func main() {
 	listenAddr := "localhost:8000"
+	grpcListenAddr := "localhost:9000"
 	router := chi.NewRouter()
 
 	withSampleData := flag.Bool("with-sample-data", false, "Initialize API with sample data")
+	dataDir := flag.String("data-dir", "", "Persist durable queues, their messages & exchanges into DATA-DIR (in memory only when empty)")
+	syncWrites := flag.Bool("sync-writes", false, "Flush every message write to disk, so that persisted messages survive an OS crash or power loss (slower)")
 	flag.Parse()
 
 	queues := map[string]*internal.Queue{}
@@ -31,11 +40,49 @@ func main() {
 		exchanges = sample.Exchanges
 	}
 
-	queueRepository := storage.NewInMemoryQueueRepository(queues)
-	exchangeRepository := storage.NewInMemoryExchangeRepository(exchanges)
+	var queueRepository storage.QueueRepository = storage.NewInMemoryQueueRepository(queues)
+	var exchangeRepository storage.ExchangeRepository = storage.NewInMemoryExchangeRepository(exchanges)
+	if *dataDir != "" {
+		fileQueueRepository, queueRepositoryErr := storage.NewFileQueueRepository(*dataDir, *syncWrites, queues)
+		if queueRepositoryErr != nil {
+			log.Fatal(queueRepositoryErr)
+		}
+
+		fileExchangeRepository, exchangeRepositoryErr := storage.NewFileExchangeRepository(*dataDir, exchanges)
+		if exchangeRepositoryErr != nil {
+			log.Fatal(exchangeRepositoryErr)
+		}
+
+		queueRepository = fileQueueRepository
+		exchangeRepository = fileExchangeRepository
+	}
+
+	if deadLetterQueueErr := routing.EnsureDeadLetterQueue(queueRepository); deadLetterQueueErr != nil {
+		log.Fatal(deadLetterQueueErr)
+	}
+
+	grpcListener, listenErr := net.Listen("tcp", grpcListenAddr)
+	if listenErr != nil {
+		log.Fatal(listenErr)
+	}
+	gs := grpcserver.NewServer(queueRepository, exchangeRepository)
+	go func() {
+		log.Fatal(gs.Serve(grpcListener))
+	}()
+
+	go func() {
+		for range time.Tick(deadLetterSweepInterval) {
+			if sweepErr := routing.SweepDeadLetters(queueRepository, exchangeRepository); sweepErr != nil {
+				log.Println("Error dead-lettering messages:", sweepErr)
+			}
+		}
+	}()
 
 	s := server.NewServer(listenAddr, router, queueRepository, exchangeRepository)
 	log.Printf("Listening on: http://%s\n", listenAddr)
+	log.Printf("Listening on: grpc://%s\n", grpcListenAddr)
 	log.Printf("With sample data: %v", *withSampleData)
+	log.Printf("Data directory: %s", *dataDir)
+	log.Printf("Sync writes: %v", *syncWrites)
 	log.Fatal(s.ListenAndServe())
 }
@@ -4,10 +4,62 @@
 
 package internal
 
-import "github.com/google/uuid"
+import (
+	"maps"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/melyouz/risala/broker/internal/util"
+)
+
+const HeadersMatchArgument = "x-match"
+const HeadersMatchAll = "all"
+const HeadersMatchAny = "any"
+
+const reservedArgumentPrefix = "x-"
 
 type Binding struct {
-	Id         uuid.UUID `json:"id"`
-	Queue      string    `json:"queue" validate:"required"`
-	RoutingKey string    `json:"routingKey"`
+	Id         uuid.UUID         `json:"id"`
+	Queue      string            `json:"queue,omitempty" validate:"required_without=Exchange,excluded_with=Exchange"`
+	Exchange   string            `json:"exchange,omitempty"`
+	RoutingKey string            `json:"routingKey"`
+	Arguments  map[string]string `json:"arguments,omitempty"`
+}
+
+// Equals reports whether both bindings route to the same destination with the same routing key & arguments
+func (b *Binding) Equals(other *Binding) bool {
+	return b.Queue == other.Queue &&
+		b.Exchange == other.Exchange &&
+		b.RoutingKey == other.RoutingKey &&
+		maps.Equal(b.Arguments, other.Arguments)
+}
+
+func (b *Binding) MatchesRoutingKey(routingKey string) bool {
+	return b.RoutingKey == routingKey
+}
+
+func (b *Binding) MatchesRoutingPattern(routingKey string) bool {
+	return util.WildcardMatch(b.RoutingKey, routingKey)
+}
+
+func (b *Binding) MatchesHeaders(headers map[string]string) bool {
+	matchAny := b.Arguments[HeadersMatchArgument] == HeadersMatchAny
+
+	for key, expectedValue := range b.Arguments {
+		if strings.HasPrefix(key, reservedArgumentPrefix) {
+			continue
+		}
+
+		value, ok := headers[key]
+		matches := ok && value == expectedValue
+		if matchAny && matches {
+			return true
+		}
+		if !matchAny && !matches {
+			return false
+		}
+	}
+
+	return !matchAny
 }
@@ -0,0 +1,28 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package internal
+
+type DeadLetterReason string
+
+var DeadLetterReasons = struct {
+	NACK                DeadLetterReason
+	EXPIRED             DeadLetterReason
+	REJECTED_OVER_LIMIT DeadLetterReason
+	MAX_DELIVERIES      DeadLetterReason
+}{
+	NACK:                "nack",
+	EXPIRED:             "expired",
+	REJECTED_OVER_LIMIT: "rejected-over-limit",
+	MAX_DELIVERIES:      "max-deliveries",
+}
+
+type PendingDeadLetter struct {
+	Message *Message
+	Reason  DeadLetterReason
+}
+
+func (r *DeadLetterReason) String() string {
+	return string(*r)
+}
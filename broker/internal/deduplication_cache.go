@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package internal
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type deduplicationEntry struct {
+	deduplicationId string
+	messageId       uuid.UUID
+	expiresAt       time.Time
+}
+
+// deduplicationCache remembers the deduplication ids seen within a window, ordered by expiry as the window is fixed
+type deduplicationCache struct {
+	sync.Mutex
+	entries map[string]*list.Element
+	order   list.List
+}
+
+// remember records the deduplication id unless already seen within the window, returning the original message id if so
+func (c *deduplicationCache) remember(deduplicationId string, messageId uuid.UUID, now time.Time, window time.Duration) (originalId uuid.UUID, duplicate bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	for e := c.order.Front(); e != nil && !now.Before(e.Value.(*deduplicationEntry).expiresAt); e = c.order.Front() {
+		delete(c.entries, e.Value.(*deduplicationEntry).deduplicationId)
+		c.order.Remove(e)
+	}
+
+	if e, ok := c.entries[deduplicationId]; ok {
+		return e.Value.(*deduplicationEntry).messageId, true
+	}
+
+	if c.entries == nil {
+		c.entries = make(map[string]*list.Element)
+	}
+	c.entries[deduplicationId] = c.order.PushBack(&deduplicationEntry{
+		deduplicationId: deduplicationId,
+		messageId:       messageId,
+		expiresAt:       now.Add(window),
+	})
+
+	return messageId, false
+}
+
+// forget drops the deduplication id recorded for the message, so that a message failing to be published can be retried
+func (c *deduplicationCache) forget(deduplicationId string, messageId uuid.UUID) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, ok := c.entries[deduplicationId]
+	if !ok || e.Value.(*deduplicationEntry).messageId != messageId {
+		return
+	}
+
+	delete(c.entries, deduplicationId)
+	c.order.Remove(e)
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package internal
+
+import (
+	"container/heap"
+)
+
+// delayedMessages is a min-heap (container/heap) of the messages not yet due, the earliest due message first
+type delayedMessages []*Message
+
+func (h delayedMessages) Len() int {
+	return len(h)
+}
+
+func (h delayedMessages) Less(i, j int) bool {
+	if h[i].AvailableAt.Equal(h[j].AvailableAt) {
+		return h[i].sequence < h[j].sequence
+	}
+
+	return h[i].AvailableAt.Before(h[j].AvailableAt)
+}
+
+func (h delayedMessages) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *delayedMessages) Push(x any) {
+	*h = append(*h, x.(*Message))
+}
+
+func (h *delayedMessages) Pop() any {
+	old := *h
+	n := len(old)
+	message := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+
+	return message
+}
+
+// earliest returns up to limit messages, the earliest due first, only visiting the part of the heap holding them
+func (h delayedMessages) earliest(limit int) (messages []*Message) {
+	messages = make([]*Message, 0, min(limit, len(h)))
+	if len(h) == 0 {
+		return messages
+	}
+
+	candidates := &delayedCandidates{messages: h, indexes: []int{0}}
+	for len(messages) < limit && candidates.Len() > 0 {
+		i := heap.Pop(candidates).(int)
+		messages = append(messages, h[i])
+		for _, child := range []int{2*i + 1, 2*i + 2} {
+			if child < len(h) {
+				heap.Push(candidates, child)
+			}
+		}
+	}
+
+	return messages
+}
+
+// delayedCandidates is a min-heap (container/heap) of indexes in delayed messages, next to be listed by earliest
+type delayedCandidates struct {
+	messages delayedMessages
+	indexes  []int
+}
+
+func (c *delayedCandidates) Len() int {
+	return len(c.indexes)
+}
+
+func (c *delayedCandidates) Less(i, j int) bool {
+	return c.messages.Less(c.indexes[i], c.indexes[j])
+}
+
+func (c *delayedCandidates) Swap(i, j int) {
+	c.indexes[i], c.indexes[j] = c.indexes[j], c.indexes[i]
+}
+
+func (c *delayedCandidates) Push(x any) {
+	c.indexes = append(c.indexes, x.(int))
+}
+
+func (c *delayedCandidates) Pop() any {
+	n := len(c.indexes)
+	i := c.indexes[n-1]
+	c.indexes = c.indexes[:n-1]
+
+	return i
+}
@@ -0,0 +1,14 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package errs
+
+const BindingCycleErrorCode = "BINDING_CYCLE"
+
+func NewBindingCycleError(msg string) *Error {
+	return &Error{
+		Code:    BindingCycleErrorCode,
+		Message: msg,
+	}
+}
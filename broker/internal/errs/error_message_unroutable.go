@@ -0,0 +1,14 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package errs
+
+const MessageUnroutableErrorCode = "MESSAGE_UNROUTABLE"
+
+func NewMessageUnroutableError(msg string) *Error {
+	return &Error{
+		Code:    MessageUnroutableErrorCode,
+		Message: msg,
+	}
+}
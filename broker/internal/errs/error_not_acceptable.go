@@ -0,0 +1,14 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package errs
+
+const NotAcceptableErrorCode = "NOT_ACCEPTABLE"
+
+func NewNotAcceptableError(msg string) *Error {
+	return &Error{
+		Code:    NotAcceptableErrorCode,
+		Message: msg,
+	}
+}
@@ -0,0 +1,15 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package errs
+
+const PayloadTooLargeErrorCode = "PAYLOAD_TOO_LARGE"
+
+func NewPayloadTooLargeError(msg string) *Error {
+	return &Error{
+		Code:    PayloadTooLargeErrorCode,
+		Param:   "payload",
+		Message: msg,
+	}
+}
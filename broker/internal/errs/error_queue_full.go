@@ -0,0 +1,14 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package errs
+
+const QueueFullErrorCode = "QUEUE_FULL"
+
+func NewQueueFullError(msg string) *Error {
+	return &Error{
+		Code:    QueueFullErrorCode,
+		Message: msg,
+	}
+}
@@ -0,0 +1,14 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package errs
+
+const StorageErrorCode = "STORAGE_ERROR"
+
+func NewStorageError(msg string) *Error {
+	return &Error{
+		Code:    StorageErrorCode,
+		Message: msg,
+	}
+}
@@ -6,6 +6,7 @@ package errs
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -28,10 +29,26 @@ func validationMessageForTag(fe validator.FieldError) string {
 	switch fe.Tag() {
 	case "required":
 		return "This field is required"
+	case "required_without":
+		return fmt.Sprintf("This field is required when %s is not set", strings.ToLower(fe.Param()))
+	case "excluded_with":
+		return fmt.Sprintf("Must not be set together with %s", strings.ToLower(fe.Param()))
 	// case "email":
 	//	return "Invalid email"
 	case "oneof":
 		return fmt.Sprintf("Invalid value '%s'. Must be one of: %s", fe.Value(), fe.Param())
+	case "gte":
+		return fmt.Sprintf("Invalid value '%v'. Must be greater than or equal to %s", fe.Value(), fe.Param())
+	case "lte":
+		return fmt.Sprintf("Invalid value '%v'. Must be less than or equal to %s", fe.Value(), fe.Param())
+	case "min":
+		return fmt.Sprintf("Must contain at least %s items", fe.Param())
+	case "max":
+		return fmt.Sprintf("Must contain at most %s items", fe.Param())
+	case "nefield":
+		return fmt.Sprintf("Invalid value '%v'. Must be different from %s", fe.Value(), strings.ToLower(fe.Param()))
+	case "uuid":
+		return fmt.Sprintf("Invalid value '%v'. Must be a UUID", fe.Value())
 	default:
 		return fe.Error()
 	}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"slices"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -16,14 +17,23 @@ import (
 
 type Exchange struct {
 	sync.RWMutex
-	Name     string     `json:"name" validate:"required"`
-	Bindings []*Binding `json:"bindings"`
+	Name                string       `json:"name" validate:"required"`
+	Type                ExchangeType `json:"type" validate:"required,oneof=direct fanout topic headers"`
+	Bindings            []*Binding   `json:"bindings"`
+	AlternateExchange   string       `json:"alternateExchange,omitempty" validate:"omitempty,nefield=Name"`
+	DeduplicationWindow int          `json:"deduplicationWindow" validate:"gte=0"`
+	deduplication       deduplicationCache
 }
 
 func (e *Exchange) Bind(binding *Binding) (err errs.AppError) {
 	e.Lock()
 	defer e.Unlock()
 
+	argumentsErr := validateBindingArguments(e, binding)
+	if argumentsErr != nil {
+		return argumentsErr
+	}
+
 	bindingErr := validateBindingDoesNotExist(e, binding)
 	if bindingErr != nil {
 		return bindingErr
@@ -48,12 +58,86 @@ func (e *Exchange) Unbind(bindingId uuid.UUID) (err errs.AppError) {
 	return errs.NewBindingNotFoundError(fmt.Sprintf("Binding '%s' not found", bindingId))
 }
 
+func (e *Exchange) GetBindings() (bindings []*Binding) {
+	e.RLock()
+	defer e.RUnlock()
+
+	return slices.Clone(e.Bindings)
+}
+
+func (e *Exchange) Route(message *Message) (bindings []*Binding) {
+	e.RLock()
+	defer e.RUnlock()
+
+	bindings = make([]*Binding, 0, len(e.Bindings))
+	for _, binding := range e.Bindings {
+		if e.matches(binding, message) {
+			bindings = append(bindings, binding)
+		}
+	}
+
+	return bindings
+}
+
+func (e *Exchange) matches(binding *Binding, message *Message) bool {
+	switch e.Type {
+	case ExchangeTypes.DIRECT:
+		return binding.MatchesRoutingKey(message.RoutingKey)
+	case ExchangeTypes.FANOUT:
+		return true
+	case ExchangeTypes.TOPIC:
+		return binding.MatchesRoutingPattern(message.RoutingKey)
+	case ExchangeTypes.HEADERS:
+		return binding.MatchesHeaders(message.Headers)
+	default:
+		return false
+	}
+}
+
+func validateBindingArguments(exchange *Exchange, binding *Binding) errs.AppError {
+	if exchange.Type != ExchangeTypes.HEADERS {
+		return nil
+	}
+
+	matchType, ok := binding.Arguments[HeadersMatchArgument]
+	if ok && matchType != HeadersMatchAll && matchType != HeadersMatchAny {
+		msg := fmt.Sprintf("Invalid value '%s' for '%s'. Must be one of: %s %s", matchType, HeadersMatchArgument, HeadersMatchAll, HeadersMatchAny)
+		return errs.NewParamInvalidError("arguments", msg)
+	}
+
+	return nil
+}
+
 func validateBindingDoesNotExist(exchange *Exchange, binding *Binding) errs.AppError {
 	for _, v := range exchange.Bindings {
-		if v.Queue == binding.Queue {
-			return errs.NewBindingExistsError(fmt.Sprintf("Binding to Queue '%s' already exists", binding.Queue))
+		if !v.Equals(binding) {
+			continue
 		}
+		if binding.Exchange != "" {
+			return errs.NewBindingExistsError(fmt.Sprintf("Binding to Exchange '%s' already exists", binding.Exchange))
+		}
+		return errs.NewBindingExistsError(fmt.Sprintf("Binding to Queue '%s' already exists", binding.Queue))
 	}
 
 	return nil
 }
+
+// Deduplicate reports whether a message with the same deduplication id went through the exchange within its
+// deduplication window, pointing the message to the id returned when publishing the original one if so
+func (e *Exchange) Deduplicate(message *Message) bool {
+	if message.DeduplicationId == "" || e.DeduplicationWindow <= 0 {
+		return false
+	}
+
+	window := time.Duration(e.DeduplicationWindow) * time.Second
+	originalId, duplicate := e.deduplication.remember(message.DeduplicationId, message.Id, time.Now(), window)
+	if duplicate {
+		message.MarkDuplicateOf(originalId)
+	}
+
+	return duplicate
+}
+
+func (e *Exchange) ForgetDeduplication(message *Message) {
+	e.deduplication.forget(message.DeduplicationId, message.Id)
+}
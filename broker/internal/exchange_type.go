@@ -0,0 +1,23 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package internal
+
+type ExchangeType string
+
+var ExchangeTypes = struct {
+	DIRECT  ExchangeType
+	FANOUT  ExchangeType
+	TOPIC   ExchangeType
+	HEADERS ExchangeType
+}{
+	DIRECT:  "direct",
+	FANOUT:  "fanout",
+	TOPIC:   "topic",
+	HEADERS: "headers",
+}
+
+func (t *ExchangeType) String() string {
+	return string(*t)
+}
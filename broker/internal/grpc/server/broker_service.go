@@ -0,0 +1,172 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+	"github.com/melyouz/risala/broker/internal/routing"
+	"github.com/melyouz/risala/broker/pkg/pb"
+)
+
+const consumeWaitInterval = time.Minute
+
+func (s *Server) PublishToQueue(_ context.Context, request *pb.PublishToQueueRequest) (*pb.Message, error) {
+	message, validationErr := s.newMessage(request.GetMessage())
+	if validationErr != nil {
+		return nil, statusFromAppError(validationErr)
+	}
+
+	queue, queueErr := s.queueRepository.GetQueue(request.GetQueue())
+	if queueErr != nil {
+		return nil, statusFromAppError(queueErr)
+	}
+
+	publishErr := queue.Enqueue(message)
+	if publishErr != nil {
+		return nil, statusFromAppError(publishErr)
+	}
+
+	return messageToPb(message), nil
+}
+
+func (s *Server) PublishToExchange(_ context.Context, request *pb.PublishToExchangeRequest) (*pb.PublishToExchangeResponse, error) {
+	message, validationErr := s.newMessage(request.GetMessage())
+	if validationErr != nil {
+		return nil, statusFromAppError(validationErr)
+	}
+
+	exchange, exchangeErr := s.exchangeRepository.GetExchange(request.GetExchange())
+	if exchangeErr != nil {
+		return nil, statusFromAppError(exchangeErr)
+	}
+
+	queueNames, publishErr := routing.PublishToExchange(s.queueRepository, s.exchangeRepository, exchange, message, request.GetMandatory())
+	if publishErr != nil {
+		return nil, statusFromAppError(publishErr)
+	}
+
+	return &pb.PublishToExchangeResponse{Message: messageToPb(message), RoutedTo: queueNames}, nil
+}
+
+func (s *Server) Consume(request *pb.ConsumeRequest, stream pb.Broker_ConsumeServer) error {
+	visibilityTimeout, paramErr := durationFromSeconds("visibilityTimeoutSeconds", request.GetVisibilityTimeoutSeconds())
+	if paramErr != nil {
+		return statusFromAppError(paramErr)
+	}
+
+	prefetch := int(request.GetPrefetch())
+	if prefetch < 0 || prefetch > internal.MaxPrefetch {
+		return statusFromAppError(errs.NewParamInvalidError("prefetch", fmt.Sprintf("Must be between 1 and %d, or 0 for the default", internal.MaxPrefetch)))
+	}
+
+	queue, queueErr := s.queueRepository.GetQueue(request.GetQueue())
+	if queueErr != nil {
+		return statusFromAppError(queueErr)
+	}
+
+	subscription := internal.NewSubscription(queue, prefetch, visibilityTimeout)
+	defer subscription.Close()
+	for {
+		message := subscription.Next(stream.Context(), consumeWaitInterval)
+		if stream.Context().Err() != nil {
+			return nil
+		}
+		if message == nil {
+			continue
+		}
+
+		if sendErr := stream.Send(messageToPb(message)); sendErr != nil {
+			return sendErr
+		}
+	}
+}
+
+func (s *Server) Ack(_ context.Context, request *pb.AckRequest) (*emptypb.Empty, error) {
+	messageId, paramErr := messageIdFromRequest(request.GetMessageId())
+	if paramErr != nil {
+		return nil, statusFromAppError(paramErr)
+	}
+
+	queue, queueErr := s.queueRepository.GetQueue(request.GetQueue())
+	if queueErr != nil {
+		return nil, statusFromAppError(queueErr)
+	}
+
+	ackErr := queue.Ack(messageId)
+	if ackErr != nil {
+		return nil, statusFromAppError(ackErr)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) Nack(_ context.Context, request *pb.NackRequest) (*emptypb.Empty, error) {
+	messageId, paramErr := messageIdFromRequest(request.GetMessageId())
+	if paramErr != nil {
+		return nil, statusFromAppError(paramErr)
+	}
+
+	delay, delayErr := durationFromSeconds("delaySeconds", request.GetDelaySeconds())
+	if delayErr != nil {
+		return nil, statusFromAppError(delayErr)
+	}
+
+	queue, queueErr := s.queueRepository.GetQueue(request.GetQueue())
+	if queueErr != nil {
+		return nil, statusFromAppError(queueErr)
+	}
+
+	nackErr := routing.Nack(s.queueRepository, s.exchangeRepository, queue, messageId, request.GetRequeue(), delay)
+	if nackErr != nil {
+		return nil, statusFromAppError(nackErr)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) newMessage(pbMessage *pb.Message) (message *internal.Message, err errs.AppError) {
+	message = messageFromPb(pbMessage)
+	message.Id = uuid.New()
+	message.Timestamp = time.Now().UTC()
+
+	var vErrors validator.ValidationErrors
+	if errors.As(s.validate.Struct(message), &vErrors) {
+		return nil, errs.NewValidationError(vErrors)
+	}
+
+	return message, nil
+}
+
+func messageIdFromRequest(rawMessageId string) (messageId uuid.UUID, err errs.AppError) {
+	messageId, uuidErr := uuid.Parse(rawMessageId)
+	if uuidErr != nil {
+		return uuid.Nil, errs.NewParamInvalidError("messageId", uuidErr.Error())
+	}
+
+	return messageId, nil
+}
+
+func durationFromSeconds(name string, seconds int64) (duration time.Duration, err errs.AppError) {
+	if seconds < 0 {
+		return 0, errs.NewParamInvalidError(name, fmt.Sprintf("Invalid duration '%d'. Must not be negative", seconds))
+	}
+
+	duration = time.Duration(seconds) * time.Second
+	if duration > internal.MaxVisibilityTimeout {
+		return 0, errs.NewParamInvalidError(name, fmt.Sprintf("Must not exceed %s", internal.MaxVisibilityTimeout))
+	}
+
+	return duration, nil
+}
@@ -0,0 +1,297 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/storage"
+	"github.com/melyouz/risala/broker/internal/testing/util"
+	"github.com/melyouz/risala/broker/pkg/pb"
+)
+
+func setupBrokerServiceTest(t *testing.T, queues map[string]*internal.Queue, exchanges map[string]*internal.Exchange) pb.BrokerClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer(storage.NewInMemoryQueueRepository(queues), storage.NewInMemoryExchangeRepository(exchanges))
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, dialErr := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.Nil(t, dialErr)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return pb.NewBrokerClient(conn)
+}
+
+func assertStatusCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+
+	s, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, code, s.Code())
+}
+
+func TestBrokerService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Publishes message to queue", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		client := setupBrokerServiceTest(t, queues, map[string]*internal.Exchange{})
+
+		message, err := client.PublishToQueue(ctx, &pb.PublishToQueueRequest{
+			Queue: "events",
+			Message: &pb.Message{
+				Payload:       []byte{0x00, 0xff},
+				ContentType:   "application/octet-stream",
+				CorrelationId: "9d1c4e2a",
+				Headers:       map[string]string{"type": "product.created"},
+			},
+		})
+
+		assert.Nil(t, err)
+		assert.NotEmpty(t, message.GetId())
+		assert.NotNil(t, message.GetTimestamp())
+		assert.Len(t, queues["events"].GetMessages(), 1)
+		assert.Equal(t, string([]byte{0x00, 0xff}), queues["events"].GetMessages()[0].Payload)
+		assert.Equal(t, "9d1c4e2a", queues["events"].GetMessages()[0].CorrelationId)
+		assert.Equal(t, "product.created", queues["events"].GetMessages()[0].Headers["type"])
+	})
+
+	t.Run("Returns invalid argument when message payload is empty", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		client := setupBrokerServiceTest(t, queues, map[string]*internal.Exchange{})
+
+		_, err := client.PublishToQueue(ctx, &pb.PublishToQueueRequest{Queue: "events", Message: &pb.Message{}})
+
+		assertStatusCode(t, err, codes.InvalidArgument)
+		assert.Equal(t, "VALIDATION_ERROR: payload: This field is required", status.Convert(err).Message())
+	})
+
+	t.Run("Returns not found when queue does not exist", func(t *testing.T) {
+		client := setupBrokerServiceTest(t, map[string]*internal.Queue{}, map[string]*internal.Exchange{})
+
+		_, err := client.PublishToQueue(ctx, &pb.PublishToQueueRequest{Queue: "events", Message: &pb.Message{Payload: []byte("Hello")}})
+
+		assertStatusCode(t, err, codes.NotFound)
+	})
+
+	t.Run("Publishes message to exchange", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"products": util.NewTestQueueDurableWithoutMessages("products"),
+			"orders":   util.NewTestQueueDurableWithoutMessages("orders"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.events": util.NewTestExchangeWithBindings("app.events", []*internal.Binding{
+				{Id: uuid.New(), Queue: "products", RoutingKey: "product.#"},
+				{Id: uuid.New(), Queue: "orders", RoutingKey: "order.#"},
+			}),
+		}
+		client := setupBrokerServiceTest(t, queues, exchanges)
+
+		response, err := client.PublishToExchange(ctx, &pb.PublishToExchangeRequest{
+			Exchange: "app.events",
+			Message:  &pb.Message{Payload: []byte("Product created"), RoutingKey: "product.created"},
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "app.events", response.GetMessage().GetExchange())
+		assert.Equal(t, []string{"products"}, response.GetRoutedTo())
+		assert.Len(t, queues["products"].GetMessages(), 1)
+		assert.Len(t, queues["orders"].GetMessages(), 0)
+	})
+
+	t.Run("Returns failed precondition when mandatory message matches no queue", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders": util.NewTestQueueDurableWithoutMessages("orders"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.events": util.NewTestExchangeWithBindings("app.events", []*internal.Binding{
+				{Id: uuid.New(), Queue: "orders", RoutingKey: "order.#"},
+			}),
+		}
+		client := setupBrokerServiceTest(t, queues, exchanges)
+
+		_, err := client.PublishToExchange(ctx, &pb.PublishToExchangeRequest{
+			Exchange:  "app.events",
+			Message:   &pb.Message{Payload: []byte("Product created"), RoutingKey: "product.created"},
+			Mandatory: true,
+		})
+
+		assertStatusCode(t, err, codes.FailedPrecondition)
+		assert.Len(t, queues["orders"].GetMessages(), 0)
+	})
+
+	t.Run("Streams available messages to consumer", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		client := setupBrokerServiceTest(t, queues, map[string]*internal.Exchange{})
+		_ = queues["events"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 1"})
+
+		streamCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		stream, err := client.Consume(streamCtx, &pb.ConsumeRequest{Queue: "events", Prefetch: 2})
+		assert.Nil(t, err)
+
+		first, recvErr := stream.Recv()
+		assert.Nil(t, recvErr)
+		assert.Equal(t, []byte("Message 1"), first.GetPayload())
+		assert.Equal(t, int32(1), first.GetDeliveryCount())
+
+		_ = queues["events"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 2"})
+
+		second, recvErr := stream.Recv()
+		assert.Nil(t, recvErr)
+		assert.Equal(t, []byte("Message 2"), second.GetPayload())
+		assert.True(t, queues["events"].GetMessages()[0].IsProcessing())
+		assert.True(t, queues["events"].GetMessages()[1].IsProcessing())
+	})
+
+	t.Run("Streams next message only once unacknowledged messages fit the prefetch", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		client := setupBrokerServiceTest(t, queues, map[string]*internal.Exchange{})
+		_ = queues["events"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 1"})
+		_ = queues["events"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 2"})
+
+		streamCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		stream, err := client.Consume(streamCtx, &pb.ConsumeRequest{Queue: "events", Prefetch: 1})
+		assert.Nil(t, err)
+
+		first, recvErr := stream.Recv()
+		assert.Nil(t, recvErr)
+		assert.Equal(t, []byte("Message 1"), first.GetPayload())
+		time.Sleep(100 * time.Millisecond)
+		assert.False(t, queues["events"].GetMessages()[1].IsProcessing())
+
+		_, ackErr := client.Ack(ctx, &pb.AckRequest{Queue: "events", MessageId: first.GetId()})
+		assert.Nil(t, ackErr)
+
+		second, recvErr := stream.Recv()
+		assert.Nil(t, recvErr)
+		assert.Equal(t, []byte("Message 2"), second.GetPayload())
+	})
+
+	t.Run("Returns invalid argument when consume prefetch is out of range", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		client := setupBrokerServiceTest(t, queues, map[string]*internal.Exchange{})
+
+		stream, err := client.Consume(ctx, &pb.ConsumeRequest{Queue: "events", Prefetch: 1001})
+		assert.Nil(t, err)
+		_, recvErr := stream.Recv()
+
+		assertStatusCode(t, recvErr, codes.InvalidArgument)
+	})
+
+	t.Run("Returns invalid argument when consume visibility timeout is out of range", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		client := setupBrokerServiceTest(t, queues, map[string]*internal.Exchange{})
+
+		stream, err := client.Consume(ctx, &pb.ConsumeRequest{Queue: "events", VisibilityTimeoutSeconds: 43201})
+		assert.Nil(t, err)
+		_, recvErr := stream.Recv()
+
+		assertStatusCode(t, recvErr, codes.InvalidArgument)
+	})
+
+	t.Run("Acknowledges message", func(t *testing.T) {
+		messageId := uuid.New()
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		queues["events"].RestoreMessages([]*internal.Message{{Id: messageId, Payload: "Message 1", Processing: true}})
+		client := setupBrokerServiceTest(t, queues, map[string]*internal.Exchange{})
+
+		_, err := client.Ack(ctx, &pb.AckRequest{Queue: "events", MessageId: messageId.String()})
+
+		assert.Nil(t, err)
+		assert.Len(t, queues["events"].GetMessages(), 0)
+	})
+
+	t.Run("Returns invalid argument when message id is invalid", func(t *testing.T) {
+		client := setupBrokerServiceTest(t, map[string]*internal.Queue{}, map[string]*internal.Exchange{})
+
+		_, err := client.Ack(ctx, &pb.AckRequest{Queue: "events", MessageId: "whatever"})
+
+		assertStatusCode(t, err, codes.InvalidArgument)
+	})
+
+	t.Run("Returns not found when message does not exist", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		client := setupBrokerServiceTest(t, queues, map[string]*internal.Exchange{})
+
+		_, err := client.Ack(ctx, &pb.AckRequest{Queue: "events", MessageId: uuid.New().String()})
+
+		assertStatusCode(t, err, codes.NotFound)
+	})
+
+	t.Run("Requeues negatively acknowledged message when requested", func(t *testing.T) {
+		messageId := uuid.New()
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		queues["events"].RestoreMessages([]*internal.Message{{Id: messageId, Payload: "Message 1", Processing: true}})
+		client := setupBrokerServiceTest(t, queues, map[string]*internal.Exchange{})
+
+		_, err := client.Nack(ctx, &pb.NackRequest{Queue: "events", MessageId: messageId.String(), Requeue: true})
+
+		assert.Nil(t, err)
+		assert.Len(t, queues["events"].GetMessages(), 1)
+		assert.False(t, queues["events"].GetMessages()[0].IsProcessing())
+	})
+
+	t.Run("Dead-letters negatively acknowledged message", func(t *testing.T) {
+		messageId := uuid.New()
+		queues := map[string]*internal.Queue{
+			"events":                     util.NewTestQueueDurableWithoutMessages("events"),
+			internal.DeadLetterQueueName: util.NewTestSystemQueueWithoutMessages(internal.DeadLetterQueueName),
+		}
+		queues["events"].RestoreMessages([]*internal.Message{{Id: messageId, Payload: "Message 1", Processing: true}})
+		client := setupBrokerServiceTest(t, queues, map[string]*internal.Exchange{})
+
+		_, err := client.Nack(ctx, &pb.NackRequest{Queue: "events", MessageId: messageId.String()})
+
+		assert.Nil(t, err)
+		assert.Len(t, queues["events"].GetMessages(), 0)
+		assert.Len(t, queues[internal.DeadLetterQueueName].GetMessages(), 1)
+		assert.Equal(t, "nack", queues[internal.DeadLetterQueueName].GetMessages()[0].Headers[internal.DeathReasonHeader])
+	})
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/melyouz/risala/broker/internal/errs"
+)
+
+var grpcDefaultStatusCode = codes.Internal
+var grpcStatusCodes = map[string]codes.Code{
+	errs.ExchangeNotFoundErrorCode:  codes.NotFound,
+	errs.QueueNotFoundErrorCode:     codes.NotFound,
+	errs.QueueFullErrorCode:         codes.ResourceExhausted,
+	errs.MessageNotFoundErrorCode:   codes.NotFound,
+	errs.MessageUnroutableErrorCode: codes.FailedPrecondition,
+	errs.ParamInvalidErrorCode:      codes.InvalidArgument,
+	errs.ValidationErrorCode:        codes.InvalidArgument,
+}
+
+func statusFromAppError(err errs.AppError) error {
+	statusCode, ok := grpcStatusCodes[err.GetCode()]
+	if !ok {
+		statusCode = grpcDefaultStatusCode
+	}
+
+	message := err.GetMessage()
+	var appErr *errs.Error
+	if errors.As(err, &appErr) && appErr.Param != "" {
+		message = fmt.Sprintf("%s: %s", appErr.Param, message)
+	}
+	if errors.As(err, &appErr) && len(appErr.Errors) > 0 {
+		fieldErrors := make([]string, len(appErr.Errors))
+		for i, fieldErr := range appErr.Errors {
+			fieldErrors[i] = fmt.Sprintf("%s: %s", fieldErr.Field, fieldErr.Message)
+		}
+		message = strings.Join(fieldErrors, ", ")
+	}
+
+	return status.Error(statusCode, fmt.Sprintf("%s: %s", err.GetCode(), message))
+}
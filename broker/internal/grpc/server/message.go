@@ -0,0 +1,68 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package server
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/pkg/pb"
+)
+
+func messageFromPb(m *pb.Message) *internal.Message {
+	message := &internal.Message{
+		Payload:         string(m.GetPayload()),
+		RoutingKey:      m.GetRoutingKey(),
+		Headers:         m.GetHeaders(),
+		ContentType:     m.GetContentType(),
+		CorrelationId:   m.GetCorrelationId(),
+		ReplyTo:         m.GetReplyTo(),
+		Expiration:      int(m.GetExpirationSeconds()),
+		Priority:        int(m.GetPriority()),
+		Delay:           int(m.GetDelaySeconds()),
+		DeduplicationId: m.GetDeduplicationId(),
+		GroupId:         m.GetGroupId(),
+	}
+	if m.GetDeliverAt() != nil {
+		deliverAt := m.GetDeliverAt().AsTime()
+		message.DeliverAt = &deliverAt
+	}
+
+	return message
+}
+
+func messageToPb(m *internal.Message) *pb.Message {
+	m.Lock()
+	defer m.Unlock()
+
+	message := &pb.Message{
+		Id:                m.Id.String(),
+		Payload:           []byte(m.Payload),
+		RoutingKey:        m.RoutingKey,
+		Exchange:          m.Exchange,
+		Headers:           m.Headers,
+		ContentType:       m.ContentType,
+		CorrelationId:     m.CorrelationId,
+		ReplyTo:           m.ReplyTo,
+		DeliveryCount:     int32(m.DeliveryCount),
+		ExpirationSeconds: int64(m.Expiration),
+		Priority:          int32(m.Priority),
+		DelaySeconds:      int64(m.Delay),
+		DeduplicationId:   m.DeduplicationId,
+		Duplicate:         m.Duplicate,
+		GroupId:           m.GroupId,
+	}
+	if !m.Timestamp.IsZero() {
+		message.Timestamp = timestamppb.New(m.Timestamp)
+	}
+	if m.ExpiresAt != nil {
+		message.ExpiresAt = timestamppb.New(*m.ExpiresAt)
+	}
+	if m.DeliverAt != nil {
+		message.DeliverAt = timestamppb.New(*m.DeliverAt)
+	}
+
+	return message
+}
@@ -0,0 +1,37 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package server
+
+import (
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+
+	"github.com/melyouz/risala/broker/internal/storage"
+	"github.com/melyouz/risala/broker/internal/validation"
+	"github.com/melyouz/risala/broker/pkg/pb"
+)
+
+type Server struct {
+	pb.UnimplementedBrokerServer
+	validate           *validator.Validate
+	queueRepository    storage.QueueRepository
+	exchangeRepository storage.ExchangeRepository
+}
+
+func NewServer(
+	queuesRepository storage.QueueRepository,
+	exchangesRepository storage.ExchangeRepository,
+) *grpc.Server {
+	s := &Server{
+		validate:           validation.NewJSONValidator(),
+		queueRepository:    queuesRepository,
+		exchangeRepository: exchangesRepository,
+	}
+
+	server := grpc.NewServer()
+	pb.RegisterBrokerServer(server, s)
+
+	return server
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/melyouz/risala/broker/internal"
 	"github.com/melyouz/risala/broker/internal/errs"
 	"github.com/melyouz/risala/broker/internal/http/util"
+	"github.com/melyouz/risala/broker/internal/routing"
 	"github.com/melyouz/risala/broker/internal/storage"
 )
 
@@ -30,10 +31,12 @@ func HandleExchangeBindingAdd(exchangeRepository storage.ExchangeRepository, que
 			return
 		}
 
-		_, queueErr := queueRepository.GetQueue(binding.Queue)
-		if queueErr != nil {
-			util.Respond(w, queueErr, util.HttpStatusCodeFromAppError(queueErr))
-			return
+		if binding.Queue != "" {
+			_, queueErr := queueRepository.GetQueue(binding.Queue)
+			if queueErr != nil {
+				util.Respond(w, queueErr, util.HttpStatusCodeFromAppError(queueErr))
+				return
+			}
 		}
 
 		exchangeName := chi.URLParam(r, "exchangeName")
@@ -43,12 +46,18 @@ func HandleExchangeBindingAdd(exchangeRepository storage.ExchangeRepository, que
 			return
 		}
 
-		bindErr := exchange.Bind(&binding)
+		bindErr := routing.Bind(exchangeRepository, exchange, &binding)
 		if bindErr != nil {
 			util.Respond(w, bindErr, util.HttpStatusCodeFromAppError(bindErr))
 			return
 		}
 
+		storeErr := exchangeRepository.StoreExchange(exchange)
+		if storeErr != nil {
+			util.Respond(w, storeErr, util.HttpStatusCodeFromAppError(storeErr))
+			return
+		}
+
 		util.Respond(w, binding, http.StatusCreated)
 	}
 }
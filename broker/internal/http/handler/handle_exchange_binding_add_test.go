@@ -19,9 +19,9 @@ import (
 
 	"github.com/melyouz/risala/broker/internal"
 	"github.com/melyouz/risala/broker/internal/errs"
-	httputil "github.com/melyouz/risala/broker/internal/http/util"
 	"github.com/melyouz/risala/broker/internal/storage"
 	"github.com/melyouz/risala/broker/internal/testing/util"
+	"github.com/melyouz/risala/broker/internal/validation"
 )
 
 func setupExchangeBindingAddTest(t *testing.T, queues map[string]*internal.Queue, exchanges map[string]*internal.Exchange, exchangeName string, bindingBody []byte) (*httptest.ResponseRecorder, *http.Request) {
@@ -38,7 +38,7 @@ func setupExchangeBindingAddTest(t *testing.T, queues map[string]*internal.Queue
 	routerCtx.URLParams.Add("exchangeName", exchangeName)
 	request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routerCtx))
 
-	HandleExchangeBindingAdd(exchangeRepository, queueRepository, httputil.NewJSONValidator())(response, request)
+	HandleExchangeBindingAdd(exchangeRepository, queueRepository, validation.NewJSONValidator())(response, request)
 
 	return response, request
 }
@@ -50,6 +50,7 @@ func TestHandleExchangeBindingAdd(t *testing.T) {
 			{Id: uuid.New(), Queue: "tmp", RoutingKey: "#"},
 		}),
 		"app.external": util.NewTestExchangeWithoutBindings("app.external"),
+		"app.headers":  util.NewTestExchange("app.headers", internal.ExchangeTypes.HEADERS, []*internal.Binding{}),
 	}
 	queues := map[string]*internal.Queue{
 		"events": util.NewTestQueueDurableWithoutMessages("events"),
@@ -71,6 +72,35 @@ func TestHandleExchangeBindingAdd(t *testing.T) {
 		assert.Equal(t, "#", jsonResponse["routingKey"])
 	})
 
+	t.Run("Adds headers binding with arguments", func(t *testing.T) {
+		bindingBody, _ := json.Marshal(map[string]interface{}{
+			"queue": "events",
+			"arguments": map[string]string{
+				"x-match": "any",
+				"type":    "product.created",
+			},
+		})
+
+		response, _ := setupExchangeBindingAddTest(t, queues, exchanges, "app.headers", bindingBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, map[string]interface{}{"x-match": "any", "type": "product.created"}, jsonResponse["arguments"])
+	})
+
+	t.Run("Returns bad request when headers binding match type is invalid", func(t *testing.T) {
+		bindingBody, _ := json.Marshal(map[string]interface{}{
+			"queue": "tmp",
+			"arguments": map[string]string{
+				"x-match": "whatever",
+			},
+		})
+
+		response, _ := setupExchangeBindingAddTest(t, queues, exchanges, "app.headers", bindingBody)
+
+		util.AssertBadRequest(t, response, errs.ParamInvalidErrorCode, "Invalid value 'whatever' for 'x-match'. Must be one of: all any")
+	})
+
 	t.Run("Returns not found error when exchange does not exist", func(t *testing.T) {
 		bindingBody, _ := json.Marshal(map[string]interface{}{
 			"queue":      "events",
@@ -106,6 +136,20 @@ func TestHandleExchangeBindingAdd(t *testing.T) {
 		util.AssertConflict(t, response, "BINDING_ALREADY_EXISTS", "Binding to Queue 'tmp' already exists")
 	})
 
+	t.Run("Adds binding to an already bound queue with a different routing key", func(t *testing.T) {
+		bindingBody, _ := json.Marshal(map[string]interface{}{
+			"queue":      "tmp",
+			"routingKey": "order.#",
+		})
+
+		response, _ := setupExchangeBindingAddTest(t, queues, exchanges, "app.internal", bindingBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, "tmp", jsonResponse["queue"])
+		assert.Equal(t, "order.#", jsonResponse["routingKey"])
+	})
+
 	t.Run("Returns validation error when no queue name supplied", func(t *testing.T) {
 
 		bindingBody, _ := json.Marshal(map[string]interface{}{
@@ -115,7 +159,103 @@ func TestHandleExchangeBindingAdd(t *testing.T) {
 		response, _ := setupExchangeBindingAddTest(t, queues, exchanges, "app.internal", bindingBody)
 
 		util.AssertValidationErrors(t, response, []errs.ValidationError{
-			{"queue", "This field is required"},
+			{Field: "queue", Message: "This field is required when exchange is not set"},
+		})
+	})
+
+	t.Run("Returns validation error when both queue & exchange supplied", func(t *testing.T) {
+
+		bindingBody, _ := json.Marshal(map[string]interface{}{
+			"queue":      "events",
+			"exchange":   "app.external",
+			"routingKey": "#",
+		})
+
+		response, _ := setupExchangeBindingAddTest(t, queues, exchanges, "app.internal", bindingBody)
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "queue", Message: "Must not be set together with exchange"},
 		})
 	})
 }
+
+func TestHandleExchangeBindingAddToExchange(t *testing.T) {
+
+	newExchanges := func() map[string]*internal.Exchange {
+		return map[string]*internal.Exchange{
+			"app.internal": util.NewTestExchangeWithoutBindings("app.internal"),
+			"product": util.NewTestExchangeWithBindings("product", []*internal.Binding{
+				{Id: uuid.New(), Exchange: "product.audit", RoutingKey: "#"},
+			}),
+			"product.audit": util.NewTestExchangeWithoutBindings("product.audit"),
+		}
+	}
+	queues := map[string]*internal.Queue{}
+
+	t.Run("Adds binding to exchange when validations pass", func(t *testing.T) {
+		exchanges := newExchanges()
+		bindingBody, _ := json.Marshal(map[string]interface{}{
+			"exchange":   "product",
+			"routingKey": "product.#",
+		})
+
+		response, _ := setupExchangeBindingAddTest(t, queues, exchanges, "app.internal", bindingBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.NotEmpty(t, jsonResponse["id"])
+		assert.Equal(t, "product", jsonResponse["exchange"])
+		assert.Nil(t, jsonResponse["queue"])
+		assert.Len(t, exchanges["app.internal"].Bindings, 1)
+	})
+
+	t.Run("Returns not found error when destination exchange does not exist", func(t *testing.T) {
+		exchanges := newExchanges()
+		bindingBody, _ := json.Marshal(map[string]interface{}{
+			"exchange":   "nonExistingExchangeName",
+			"routingKey": "#",
+		})
+
+		response, _ := setupExchangeBindingAddTest(t, queues, exchanges, "app.internal", bindingBody)
+
+		util.AssertNotFound(t, response, "EXCHANGE_NOT_FOUND", "Exchange 'nonExistingExchangeName' not found")
+	})
+
+	t.Run("Returns conflict error when binding to exchange already exists", func(t *testing.T) {
+		exchanges := newExchanges()
+		bindingBody, _ := json.Marshal(map[string]interface{}{
+			"exchange":   "product.audit",
+			"routingKey": "#",
+		})
+
+		response, _ := setupExchangeBindingAddTest(t, queues, exchanges, "product", bindingBody)
+
+		util.AssertConflict(t, response, "BINDING_ALREADY_EXISTS", "Binding to Exchange 'product.audit' already exists")
+	})
+
+	t.Run("Returns conflict error when binding exchange to itself", func(t *testing.T) {
+		exchanges := newExchanges()
+		bindingBody, _ := json.Marshal(map[string]interface{}{
+			"exchange":   "app.internal",
+			"routingKey": "#",
+		})
+
+		response, _ := setupExchangeBindingAddTest(t, queues, exchanges, "app.internal", bindingBody)
+
+		util.AssertConflict(t, response, errs.BindingCycleErrorCode, "Binding to Exchange 'app.internal' would create a cycle")
+		assert.Len(t, exchanges["app.internal"].Bindings, 0)
+	})
+
+	t.Run("Returns conflict error when binding would create a cycle", func(t *testing.T) {
+		exchanges := newExchanges()
+		bindingBody, _ := json.Marshal(map[string]interface{}{
+			"exchange":   "product",
+			"routingKey": "#",
+		})
+
+		response, _ := setupExchangeBindingAddTest(t, queues, exchanges, "product.audit", bindingBody)
+
+		util.AssertConflict(t, response, errs.BindingCycleErrorCode, "Binding to Exchange 'product' would create a cycle")
+		assert.Len(t, exchanges["product.audit"].Bindings, 0)
+	})
+}
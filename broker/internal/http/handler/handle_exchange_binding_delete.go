@@ -38,6 +38,12 @@ func HandleExchangeBindingDelete(exchangeRepository storage.ExchangeRepository)
 			return
 		}
 
+		storeErr := exchangeRepository.StoreExchange(exchange)
+		if storeErr != nil {
+			util.Respond(w, storeErr, util.HttpStatusCodeFromAppError(storeErr))
+			return
+		}
+
 		util.Respond(w, nil, http.StatusNoContent)
 	}
 }
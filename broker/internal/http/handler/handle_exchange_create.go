@@ -10,14 +10,16 @@ import (
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 
 	"github.com/melyouz/risala/broker/internal"
 	"github.com/melyouz/risala/broker/internal/errs"
 	"github.com/melyouz/risala/broker/internal/http/util"
+	"github.com/melyouz/risala/broker/internal/routing"
 	"github.com/melyouz/risala/broker/internal/storage"
 )
 
-func HandleExchangeCreate(exchangeRepository storage.ExchangeRepository, validate *validator.Validate) http.HandlerFunc {
+func HandleExchangeCreate(exchangeRepository storage.ExchangeRepository, queueRepository storage.QueueRepository, validate *validator.Validate) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var exchange internal.Exchange
 		util.Decode(r, &exchange)
@@ -28,10 +30,6 @@ func HandleExchangeCreate(exchangeRepository storage.ExchangeRepository, validat
 			return
 		}
 
-		if exchange.Bindings == nil {
-			exchange.Bindings = []*internal.Binding{}
-		}
-
 		existingExchange, _ := exchangeRepository.GetExchange(exchange.Name)
 		if existingExchange != nil {
 			existsErr := errs.NewExchangeExistsError(fmt.Sprintf("Exchange '%s' already exists", exchange.Name))
@@ -39,7 +37,45 @@ func HandleExchangeCreate(exchangeRepository storage.ExchangeRepository, validat
 			return
 		}
 
-		exchangeRepository.StoreExchange(&exchange)
+		// supplied bindings go through the same checks as the ones added once the exchange exists
+		bindings := exchange.Bindings
+		exchange.Bindings = []*internal.Binding{}
+		for _, binding := range bindings {
+			if binding == nil {
+				bindingErr := errs.NewParamInvalidError("bindings", "Bindings must not be null")
+				util.Respond(w, bindingErr, util.HttpStatusCodeFromAppError(bindingErr))
+				return
+			}
+
+			if errors.As(validate.Struct(binding), &vErrors) {
+				util.Respond(w, errs.NewValidationError(vErrors), http.StatusUnprocessableEntity)
+				return
+			}
+
+			if binding.Queue != "" {
+				_, queueErr := queueRepository.GetQueue(binding.Queue)
+				if queueErr != nil {
+					util.Respond(w, queueErr, util.HttpStatusCodeFromAppError(queueErr))
+					return
+				}
+			}
+
+			if binding.Id == uuid.Nil {
+				binding.Id = uuid.New()
+			}
+
+			bindErr := routing.Bind(exchangeRepository, &exchange, binding)
+			if bindErr != nil {
+				util.Respond(w, bindErr, util.HttpStatusCodeFromAppError(bindErr))
+				return
+			}
+		}
+
+		storeErr := exchangeRepository.StoreExchange(&exchange)
+		if storeErr != nil {
+			util.Respond(w, storeErr, util.HttpStatusCodeFromAppError(storeErr))
+			return
+		}
 
 		util.Respond(w, &exchange, http.StatusCreated)
 	}
@@ -11,29 +11,35 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/melyouz/risala/broker/internal"
 	"github.com/melyouz/risala/broker/internal/errs"
-	httputil "github.com/melyouz/risala/broker/internal/http/util"
 	"github.com/melyouz/risala/broker/internal/storage"
 	"github.com/melyouz/risala/broker/internal/testing/util"
+	"github.com/melyouz/risala/broker/internal/validation"
 )
 
-func setupExchangeCreateTest(t *testing.T, exchanges map[string]*internal.Exchange, body map[string]interface{}) (*httptest.ResponseRecorder, *http.Request) {
+func setupExchangeCreateTest(t *testing.T, queues map[string]*internal.Queue, exchanges map[string]*internal.Exchange, body map[string]interface{}) (*httptest.ResponseRecorder, *http.Request) {
 	t.Helper()
 
+	queueRepository := storage.NewInMemoryQueueRepository(queues)
 	exchangeRepository := storage.NewInMemoryExchangeRepository(exchanges)
 	exchangeBody, _ := json.Marshal(body)
 	request := httptest.NewRequest(http.MethodPost, util.ApiV1BasePath+"/exchanges", bytes.NewReader(exchangeBody))
 	response := httptest.NewRecorder()
 
-	HandleExchangeCreate(exchangeRepository, httputil.NewJSONValidator())(response, request)
+	HandleExchangeCreate(exchangeRepository, queueRepository, validation.NewJSONValidator())(response, request)
 
 	return response, request
 }
 
 func TestHandleExchangeCreate(t *testing.T) {
+	queues := map[string]*internal.Queue{
+		"events": util.NewTestQueueDurableWithoutMessages("events"),
+	}
+
 	t.Run("Creates exchange when validations pass", func(t *testing.T) {
 		exchanges := map[string]*internal.Exchange{
 			"app.internal": util.NewTestExchangeWithoutBindings("app.internal"),
@@ -41,25 +47,127 @@ func TestHandleExchangeCreate(t *testing.T) {
 		}
 		exchangeBody := map[string]interface{}{
 			"name": "app.tmp",
+			"type": internal.ExchangeTypes.TOPIC.String(),
 		}
 
-		response, _ := setupExchangeCreateTest(t, exchanges, exchangeBody)
+		response, _ := setupExchangeCreateTest(t, queues, exchanges, exchangeBody)
 
 		util.AssertCreated(t, response)
 		jsonResponse := util.JSONItemResponse(response)
 		assert.Equal(t, "app.tmp", jsonResponse["name"])
+		assert.Equal(t, internal.ExchangeTypes.TOPIC.String(), jsonResponse["type"])
 		assert.Empty(t, jsonResponse["bindings"])
 	})
 
+	t.Run("Creates exchange of every supported type", func(t *testing.T) {
+		exchangeTypes := []internal.ExchangeType{
+			internal.ExchangeTypes.DIRECT,
+			internal.ExchangeTypes.FANOUT,
+			internal.ExchangeTypes.TOPIC,
+			internal.ExchangeTypes.HEADERS,
+		}
+
+		for _, exchangeType := range exchangeTypes {
+			exchanges := map[string]*internal.Exchange{}
+			exchangeBody := map[string]interface{}{
+				"name": "app.tmp",
+				"type": exchangeType.String(),
+			}
+
+			response, _ := setupExchangeCreateTest(t, queues, exchanges, exchangeBody)
+
+			util.AssertCreated(t, response)
+			jsonResponse := util.JSONItemResponse(response)
+			assert.Equal(t, exchangeType.String(), jsonResponse["type"])
+		}
+	})
+
+	t.Run("Creates exchange with alternate exchange", func(t *testing.T) {
+		exchanges := map[string]*internal.Exchange{}
+		exchangeBody := map[string]interface{}{
+			"name":              "app.tmp",
+			"type":              internal.ExchangeTypes.DIRECT.String(),
+			"alternateExchange": "app.unroutable",
+		}
+
+		response, _ := setupExchangeCreateTest(t, queues, exchanges, exchangeBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, "app.unroutable", jsonResponse["alternateExchange"])
+		assert.Equal(t, "app.unroutable", exchanges["app.tmp"].AlternateExchange)
+	})
+
+	t.Run("Returns validation error when alternate exchange is the exchange itself", func(t *testing.T) {
+		exchanges := map[string]*internal.Exchange{}
+		exchangeBody := map[string]interface{}{
+			"name":              "app.tmp",
+			"type":              internal.ExchangeTypes.DIRECT.String(),
+			"alternateExchange": "app.tmp",
+		}
+
+		response, _ := setupExchangeCreateTest(t, queues, exchanges, exchangeBody)
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "alternateExchange", Message: "Invalid value 'app.tmp'. Must be different from name"},
+		})
+	})
+
+	t.Run("Returns validation error when exchange deduplication window is negative", func(t *testing.T) {
+		exchanges := map[string]*internal.Exchange{}
+		exchangeBody := map[string]interface{}{
+			"name":                "app.tmp",
+			"type":                internal.ExchangeTypes.DIRECT.String(),
+			"deduplicationWindow": -1,
+		}
+
+		response, _ := setupExchangeCreateTest(t, queues, exchanges, exchangeBody)
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "deduplicationWindow", Message: "Invalid value '-1'. Must be greater than or equal to 0"},
+		})
+	})
+
+	t.Run("Returns validation error when unknown exchange type", func(t *testing.T) {
+
+		exchanges := map[string]*internal.Exchange{}
+		exchangeBody := map[string]interface{}{
+			"name": "app.tmp",
+			"type": "whatever",
+		}
+
+		response, _ := setupExchangeCreateTest(t, queues, exchanges, exchangeBody)
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "type", Message: "Invalid value 'whatever'. Must be one of: direct fanout topic headers"},
+		})
+	})
+
+	t.Run("Returns validation error when no exchange type supplied", func(t *testing.T) {
+
+		exchanges := map[string]*internal.Exchange{}
+		exchangeBody := map[string]interface{}{
+			"name": "app.tmp",
+		}
+
+		response, _ := setupExchangeCreateTest(t, queues, exchanges, exchangeBody)
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "type", Message: "This field is required"},
+		})
+	})
+
 	t.Run("Returns validation error when no exchange name supplied", func(t *testing.T) {
 
 		exchanges := map[string]*internal.Exchange{}
-		exchangeBody := map[string]interface{}{}
+		exchangeBody := map[string]interface{}{
+			"type": internal.ExchangeTypes.TOPIC.String(),
+		}
 
-		response, _ := setupExchangeCreateTest(t, exchanges, exchangeBody)
+		response, _ := setupExchangeCreateTest(t, queues, exchanges, exchangeBody)
 
 		util.AssertValidationErrors(t, response, []errs.ValidationError{
-			{"name", "This field is required"},
+			{Field: "name", Message: "This field is required"},
 		})
 	})
 
@@ -68,12 +176,13 @@ func TestHandleExchangeCreate(t *testing.T) {
 		exchanges := map[string]*internal.Exchange{}
 		exchangeBody := map[string]interface{}{
 			"name": "",
+			"type": internal.ExchangeTypes.TOPIC.String(),
 		}
 
-		response, _ := setupExchangeCreateTest(t, exchanges, exchangeBody)
+		response, _ := setupExchangeCreateTest(t, queues, exchanges, exchangeBody)
 
 		util.AssertValidationErrors(t, response, []errs.ValidationError{
-			{"name", "This field is required"},
+			{Field: "name", Message: "This field is required"},
 		})
 	})
 
@@ -82,13 +191,119 @@ func TestHandleExchangeCreate(t *testing.T) {
 		exchanges := map[string]*internal.Exchange{}
 		exchangeBody := map[string]interface{}{
 			"name": nil,
+			"type": internal.ExchangeTypes.TOPIC.String(),
+		}
+
+		response, _ := setupExchangeCreateTest(t, queues, exchanges, exchangeBody)
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "name", Message: "This field is required"},
+		})
+	})
+
+	t.Run("Creates exchange with bindings", func(t *testing.T) {
+		exchanges := map[string]*internal.Exchange{
+			"app.internal": util.NewTestExchangeWithoutBindings("app.internal"),
+		}
+		exchangeBody := map[string]interface{}{
+			"name": "app.tmp",
+			"type": internal.ExchangeTypes.TOPIC.String(),
+			"bindings": []map[string]interface{}{
+				{"queue": "events", "routingKey": "#"},
+				{"exchange": "app.internal", "routingKey": "#"},
+			},
+		}
+
+		response, _ := setupExchangeCreateTest(t, queues, exchanges, exchangeBody)
+
+		util.AssertCreated(t, response)
+		assert.Len(t, exchanges["app.tmp"].Bindings, 2)
+		assert.Equal(t, "events", exchanges["app.tmp"].Bindings[0].Queue)
+		assert.NotEqual(t, uuid.Nil, exchanges["app.tmp"].Bindings[0].Id)
+		assert.Equal(t, "app.internal", exchanges["app.tmp"].Bindings[1].Exchange)
+	})
+
+	t.Run("Returns validation error when supplied binding has no destination", func(t *testing.T) {
+		exchanges := map[string]*internal.Exchange{}
+		exchangeBody := map[string]interface{}{
+			"name": "app.tmp",
+			"type": internal.ExchangeTypes.TOPIC.String(),
+			"bindings": []map[string]interface{}{
+				{"routingKey": "#"},
+			},
 		}
 
-		response, _ := setupExchangeCreateTest(t, exchanges, exchangeBody)
+		response, _ := setupExchangeCreateTest(t, queues, exchanges, exchangeBody)
 
 		util.AssertValidationErrors(t, response, []errs.ValidationError{
-			{"name", "This field is required"},
+			{Field: "queue", Message: "This field is required when exchange is not set"},
 		})
+		assert.NotContains(t, exchanges, "app.tmp")
+	})
+
+	t.Run("Returns not found error when supplied binding queue does not exist", func(t *testing.T) {
+		exchanges := map[string]*internal.Exchange{}
+		exchangeBody := map[string]interface{}{
+			"name": "app.tmp",
+			"type": internal.ExchangeTypes.TOPIC.String(),
+			"bindings": []map[string]interface{}{
+				{"queue": "nonExistingQueueName", "routingKey": "#"},
+			},
+		}
+
+		response, _ := setupExchangeCreateTest(t, queues, exchanges, exchangeBody)
+
+		util.AssertNotFound(t, response, "QUEUE_NOT_FOUND", "Queue 'nonExistingQueueName' not found")
+		assert.NotContains(t, exchanges, "app.tmp")
+	})
+
+	t.Run("Returns not found error when supplied binding destination exchange does not exist", func(t *testing.T) {
+		exchanges := map[string]*internal.Exchange{}
+		exchangeBody := map[string]interface{}{
+			"name": "app.tmp",
+			"type": internal.ExchangeTypes.TOPIC.String(),
+			"bindings": []map[string]interface{}{
+				{"exchange": "nonExistingExchangeName", "routingKey": "#"},
+			},
+		}
+
+		response, _ := setupExchangeCreateTest(t, queues, exchanges, exchangeBody)
+
+		util.AssertNotFound(t, response, "EXCHANGE_NOT_FOUND", "Exchange 'nonExistingExchangeName' not found")
+		assert.NotContains(t, exchanges, "app.tmp")
+	})
+
+	t.Run("Returns conflict error when supplied binding binds exchange to itself", func(t *testing.T) {
+		exchanges := map[string]*internal.Exchange{}
+		exchangeBody := map[string]interface{}{
+			"name": "app.tmp",
+			"type": internal.ExchangeTypes.TOPIC.String(),
+			"bindings": []map[string]interface{}{
+				{"exchange": "app.tmp", "routingKey": "#"},
+			},
+		}
+
+		response, _ := setupExchangeCreateTest(t, queues, exchanges, exchangeBody)
+
+		util.AssertConflict(t, response, errs.BindingCycleErrorCode, "Binding to Exchange 'app.tmp' would create a cycle")
+		assert.NotContains(t, exchanges, "app.tmp")
+	})
+
+	t.Run("Returns conflict error when same binding supplied twice", func(t *testing.T) {
+		exchanges := map[string]*internal.Exchange{}
+		exchangeBody := map[string]interface{}{
+			"name": "app.tmp",
+			"type": internal.ExchangeTypes.TOPIC.String(),
+			"bindings": []map[string]interface{}{
+				{"queue": "events", "routingKey": "#"},
+				{"queue": "events", "routingKey": "#"},
+			},
+		}
+
+		response, _ := setupExchangeCreateTest(t, queues, exchanges, exchangeBody)
+
+		util.AssertConflict(t, response, errs.BindingExistsErrorCode, "Binding to Queue 'events' already exists")
+		assert.NotContains(t, exchanges, "app.tmp")
 	})
 
 	t.Run("Returns conflict error when exchange already exists", func(t *testing.T) {
@@ -99,9 +314,10 @@ func TestHandleExchangeCreate(t *testing.T) {
 		}
 		exchangeBody := map[string]interface{}{
 			"name": "app.internal",
+			"type": internal.ExchangeTypes.TOPIC.String(),
 		}
 
-		response, _ := setupExchangeCreateTest(t, exchanges, exchangeBody)
+		response, _ := setupExchangeCreateTest(t, queues, exchanges, exchangeBody)
 
 		util.AssertConflict(t, response, "EXCHANGE_ALREADY_EXISTS", "Exchange 'app.internal' already exists")
 	})
@@ -7,6 +7,7 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
@@ -15,6 +16,7 @@ import (
 	"github.com/melyouz/risala/broker/internal"
 	"github.com/melyouz/risala/broker/internal/errs"
 	"github.com/melyouz/risala/broker/internal/http/util"
+	"github.com/melyouz/risala/broker/internal/routing"
 	"github.com/melyouz/risala/broker/internal/storage"
 )
 
@@ -26,7 +28,11 @@ func HandleExchangeMessagePublish(
 	return func(w http.ResponseWriter, r *http.Request) {
 		var message internal.Message
 		message.Id = uuid.New()
-		util.Decode(r, &message)
+		if decodeErr := util.DecodeMessage(r, &message); decodeErr != nil {
+			util.Respond(w, decodeErr, util.HttpStatusCodeFromAppError(decodeErr))
+			return
+		}
+		message.Timestamp = time.Now().UTC()
 
 		var vErrors validator.ValidationErrors
 		if errors.As(validate.Struct(&message), &vErrors) {
@@ -34,6 +40,12 @@ func HandleExchangeMessagePublish(
 			return
 		}
 
+		mandatory, paramErr := util.BoolQueryParam(r, "mandatory")
+		if paramErr != nil {
+			util.Respond(w, paramErr, util.HttpStatusCodeFromAppError(paramErr))
+			return
+		}
+
 		exchangeName := chi.URLParam(r, "exchangeName")
 		exchange, exchangeErr := exchangeRepository.GetExchange(exchangeName)
 		if exchangeErr != nil {
@@ -41,20 +53,17 @@ func HandleExchangeMessagePublish(
 			return
 		}
 
-		for _, binding := range exchange.Bindings {
-			queue, queueErr := queueRepository.GetQueue(binding.Queue)
-			if queueErr != nil {
-				util.Respond(w, queueErr, util.HttpStatusCodeFromAppError(queueErr))
-				return
-			}
-
-			publishErr := queue.Enqueue(&message)
-			if publishErr != nil {
-				util.Respond(w, publishErr, util.HttpStatusCodeFromAppError(publishErr))
-				return
-			}
+		queueNames, publishErr := routing.PublishToExchange(queueRepository, exchangeRepository, exchange, &message, mandatory)
+		if publishErr != nil {
+			util.Respond(w, publishErr, util.HttpStatusCodeFromAppError(publishErr))
+			return
 		}
 
-		util.Respond(w, &message, http.StatusCreated)
+		util.Respond(w, &routedMessage{Message: &message, RoutedTo: queueNames}, publishedStatusCode(&message))
 	}
 }
+
+type routedMessage struct {
+	Message  *internal.Message `json:"message"`
+	RoutedTo []string          `json:"routedTo"`
+}
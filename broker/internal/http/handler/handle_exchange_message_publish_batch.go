@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/http/util"
+	"github.com/melyouz/risala/broker/internal/routing"
+	"github.com/melyouz/risala/broker/internal/storage"
+)
+
+func HandleExchangeMessagePublishBatch(
+	exchangeRepository storage.ExchangeRepository,
+	queueRepository storage.QueueRepository,
+	validate *validator.Validate,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var messages []*internal.Message
+		if decodeErr := util.DecodeMessages(r, &messages); decodeErr != nil {
+			util.Respond(w, decodeErr, util.HttpStatusCodeFromAppError(decodeErr))
+			return
+		}
+
+		mandatory, paramErr := util.BoolQueryParam(r, "mandatory")
+		if paramErr != nil {
+			util.Respond(w, paramErr, util.HttpStatusCodeFromAppError(paramErr))
+			return
+		}
+
+		exchangeName := chi.URLParam(r, "exchangeName")
+		exchange, exchangeErr := exchangeRepository.GetExchange(exchangeName)
+		if exchangeErr != nil {
+			util.Respond(w, exchangeErr, util.HttpStatusCodeFromAppError(exchangeErr))
+			return
+		}
+
+		results := make([]messageBatchResult, len(messages))
+		for i, message := range messages {
+			var queueNames []string
+			publishErr := validateBatchMessage(validate, message)
+			if publishErr == nil {
+				queueNames, publishErr = routing.PublishToExchange(queueRepository, exchangeRepository, exchange, message, mandatory)
+			}
+			results[i] = publishedMessageResult(message, publishErr)
+			if publishErr == nil {
+				results[i].RoutedTo = queueNames
+			}
+		}
+
+		util.Respond(w, results, http.StatusOK)
+	}
+}
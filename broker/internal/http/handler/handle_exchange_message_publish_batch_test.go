@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+	"github.com/melyouz/risala/broker/internal/storage"
+	"github.com/melyouz/risala/broker/internal/testing/util"
+	"github.com/melyouz/risala/broker/internal/validation"
+)
+
+func setupExchangeMessagePublishBatchTest(t *testing.T, queues map[string]*internal.Queue, exchanges map[string]*internal.Exchange, exchangeName string, messagesBody []byte) (*httptest.ResponseRecorder, *http.Request) {
+	t.Helper()
+
+	return setupExchangeMessagePublishBatchWithQueryTest(t, queues, exchanges, exchangeName, "", messagesBody)
+}
+
+func setupExchangeMessagePublishBatchWithQueryTest(t *testing.T, queues map[string]*internal.Queue, exchanges map[string]*internal.Exchange, exchangeName string, query string, messagesBody []byte) (*httptest.ResponseRecorder, *http.Request) {
+	t.Helper()
+
+	queueRepository := storage.NewInMemoryQueueRepository(queues)
+	exchangeRepository := storage.NewInMemoryExchangeRepository(exchanges)
+
+	path := fmt.Sprintf("%s/exchanges/%s/messages/publish-batch%s", util.ApiV1BasePath, exchangeName, query)
+	request := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(messagesBody))
+	response := httptest.NewRecorder()
+
+	routerCtx := chi.NewRouteContext()
+	routerCtx.URLParams.Add("exchangeName", exchangeName)
+	request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routerCtx))
+
+	HandleExchangeMessagePublishBatch(exchangeRepository, queueRepository, validation.NewJSONValidator())(response, request)
+
+	return response, request
+}
+
+func TestHandleExchangeMessagePublishBatch(t *testing.T) {
+
+	t.Run("Routes each message by its routing key & reports result per message", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"products": util.NewTestQueueDurableWithoutMessages("products"),
+			"orders":   util.NewTestQueueDurableWithoutMessages("orders"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.events": util.NewTestExchangeWithBindings("app.events", []*internal.Binding{
+				{Id: uuid.New(), Queue: "products", RoutingKey: "product.#"},
+				{Id: uuid.New(), Queue: "orders", RoutingKey: "order.#"},
+			}),
+		}
+		messagesBody, _ := json.Marshal([]map[string]interface{}{
+			{"payload": "Product created", "routingKey": "product.created"},
+			{"payload": "Order placed", "routingKey": "order.placed"},
+			{"routingKey": "order.cancelled"},
+		})
+
+		response, _ := setupExchangeMessagePublishBatchTest(t, queues, exchanges, "app.events", messagesBody)
+
+		util.AssertOk(t, response)
+		jsonResponse := util.JSONCollectionResponse(response)
+		assert.Len(t, jsonResponse, 3)
+		assert.Equal(t, true, jsonResponse[0]["success"])
+		assert.Equal(t, true, jsonResponse[1]["success"])
+		assert.Equal(t, false, jsonResponse[2]["success"])
+		assert.Len(t, queues["products"].GetMessages(), 1)
+		assert.Len(t, queues["orders"].GetMessages(), 1)
+		assert.Equal(t, queues["products"].GetMessages()[0].Id.String(), jsonResponse[0]["messageId"])
+		assert.Equal(t, []interface{}{"products"}, jsonResponse[0]["routedTo"])
+		assert.Equal(t, "app.events", queues["orders"].GetMessages()[0].Exchange)
+	})
+
+	t.Run("Reports unroutable mandatory messages as failed", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders": util.NewTestQueueDurableWithoutMessages("orders"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.events": util.NewTestExchangeWithBindings("app.events", []*internal.Binding{
+				{Id: uuid.New(), Queue: "orders", RoutingKey: "order.#"},
+			}),
+		}
+		messagesBody, _ := json.Marshal([]map[string]interface{}{
+			{"payload": "Order placed", "routingKey": "order.placed"},
+			{"payload": "Product created", "routingKey": "product.created"},
+		})
+
+		response, _ := setupExchangeMessagePublishBatchWithQueryTest(t, queues, exchanges, "app.events", "?mandatory=true", messagesBody)
+
+		util.AssertOk(t, response)
+		jsonResponse := util.JSONCollectionResponse(response)
+		assert.Equal(t, true, jsonResponse[0]["success"])
+		assert.Equal(t, []interface{}{"orders"}, jsonResponse[0]["routedTo"])
+		assert.Equal(t, false, jsonResponse[1]["success"])
+		assert.Equal(t, errs.MessageUnroutableErrorCode, jsonResponse[1]["error"].(map[string]interface{})["code"])
+		assert.Len(t, queues["orders"].GetMessages(), 1)
+	})
+
+	t.Run("Returns not found when exchange does not exist", func(t *testing.T) {
+		messagesBody, _ := json.Marshal([]map[string]interface{}{{"payload": "Hello"}})
+
+		response, _ := setupExchangeMessagePublishBatchTest(t, map[string]*internal.Queue{}, map[string]*internal.Exchange{}, "nonExistingExchangeName", messagesBody)
+
+		util.AssertNotFound(t, response, "EXCHANGE_NOT_FOUND", "Exchange 'nonExistingExchangeName' not found")
+	})
+}
@@ -7,10 +7,12 @@ package handler
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
@@ -19,18 +21,24 @@ import (
 
 	"github.com/melyouz/risala/broker/internal"
 	"github.com/melyouz/risala/broker/internal/errs"
-	httputil "github.com/melyouz/risala/broker/internal/http/util"
 	"github.com/melyouz/risala/broker/internal/storage"
 	"github.com/melyouz/risala/broker/internal/testing/util"
+	"github.com/melyouz/risala/broker/internal/validation"
 )
 
 func setupExchangeMessagePublishTest(t *testing.T, queues map[string]*internal.Queue, exchanges map[string]*internal.Exchange, exchangeName string, messageBody []byte) (*httptest.ResponseRecorder, *http.Request) {
 	t.Helper()
 
+	return setupExchangeMessagePublishWithQueryTest(t, queues, exchanges, exchangeName, "", messageBody)
+}
+
+func setupExchangeMessagePublishWithQueryTest(t *testing.T, queues map[string]*internal.Queue, exchanges map[string]*internal.Exchange, exchangeName string, query string, messageBody []byte) (*httptest.ResponseRecorder, *http.Request) {
+	t.Helper()
+
 	queueRepository := storage.NewInMemoryQueueRepository(queues)
 	exchangeRepository := storage.NewInMemoryExchangeRepository(exchanges)
 
-	path := fmt.Sprintf("%s/exchanges/%s/messages/publish", util.ApiV1BasePath, exchangeName)
+	path := fmt.Sprintf("%s/exchanges/%s/messages/publish%s", util.ApiV1BasePath, exchangeName, query)
 	request := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(messageBody))
 	response := httptest.NewRecorder()
 
@@ -38,7 +46,29 @@ func setupExchangeMessagePublishTest(t *testing.T, queues map[string]*internal.Q
 	routerCtx.URLParams.Add("exchangeName", exchangeName)
 	request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routerCtx))
 
-	HandleExchangeMessagePublish(exchangeRepository, queueRepository, httputil.NewJSONValidator())(response, request)
+	HandleExchangeMessagePublish(exchangeRepository, queueRepository, validation.NewJSONValidator())(response, request)
+
+	return response, request
+}
+
+func setupExchangeMessagePublishRawTest(t *testing.T, queues map[string]*internal.Queue, exchanges map[string]*internal.Exchange, exchangeName string, body []byte, headers map[string]string) (*httptest.ResponseRecorder, *http.Request) {
+	t.Helper()
+
+	queueRepository := storage.NewInMemoryQueueRepository(queues)
+	exchangeRepository := storage.NewInMemoryExchangeRepository(exchanges)
+
+	path := fmt.Sprintf("%s/exchanges/%s/messages/publish", util.ApiV1BasePath, exchangeName)
+	request := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	response := httptest.NewRecorder()
+
+	routerCtx := chi.NewRouteContext()
+	routerCtx.URLParams.Add("exchangeName", exchangeName)
+	request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routerCtx))
+
+	HandleExchangeMessagePublish(exchangeRepository, queueRepository, validation.NewJSONValidator())(response, request)
 
 	return response, request
 }
@@ -62,15 +92,592 @@ func TestHandleExchangeMessagePublish(t *testing.T) {
 			"payload": "Hello world from Exchange",
 		})
 
-		tmpQueueMessagesCount := len(queues["tmp"].Messages)
+		tmpQueueMessagesCount := len(queues["tmp"].GetMessages())
 		response, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.internal", messageBody)
 
 		util.AssertCreated(t, response)
-		var jsonResponse map[string]interface{}
-		_ = json.Unmarshal(response.Body.Bytes(), &jsonResponse)
-		assert.NotEmpty(t, jsonResponse["id"])
-		assert.Equal(t, "Hello world from Exchange", jsonResponse["payload"])
-		assert.Len(t, queues["tmp"].Messages, tmpQueueMessagesCount+1)
+		jsonResponse := util.JSONItemResponse(response)
+		jsonMessage := jsonResponse["message"].(map[string]interface{})
+		assert.NotEmpty(t, jsonMessage["id"])
+		assert.Equal(t, "Hello world from Exchange", jsonMessage["payload"])
+		assert.Len(t, queues["tmp"].GetMessages(), tmpQueueMessagesCount+1)
+	})
+
+	t.Run("Publishes message only to queues whose binding routing key matches", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"products": util.NewTestQueueDurableWithoutMessages("products"),
+			"orders":   util.NewTestQueueDurableWithoutMessages("orders"),
+			"all":      util.NewTestQueueDurableWithoutMessages("all"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.events": util.NewTestExchangeWithBindings("app.events", []*internal.Binding{
+				{Id: uuid.New(), Queue: "products", RoutingKey: "product.#"},
+				{Id: uuid.New(), Queue: "orders", RoutingKey: "order.*"},
+				{Id: uuid.New(), Queue: "all", RoutingKey: "#"},
+			}),
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":    "Product created",
+			"routingKey": "product.created.v1",
+		})
+
+		response, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.events", messageBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		jsonMessage := jsonResponse["message"].(map[string]interface{})
+		assert.Equal(t, "product.created.v1", jsonMessage["routingKey"])
+		assert.Equal(t, "app.events", jsonMessage["exchange"])
+		assert.NotEmpty(t, jsonMessage["timestamp"])
+		assert.Equal(t, "app.events", queues["products"].GetMessages()[0].Exchange)
+		assert.Equal(t, "product.created.v1", queues["products"].GetMessages()[0].RoutingKey)
+		assert.Len(t, queues["products"].GetMessages(), 1)
+		assert.Len(t, queues["orders"].GetMessages(), 0)
+		assert.Len(t, queues["all"].GetMessages(), 1)
+	})
+
+	t.Run("Returns original message when published again within the exchange deduplication window", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders":  util.NewTestQueueDurableWithoutMessages("orders"),
+			"billing": util.NewTestQueueDurableWithoutMessages("billing"),
+		}
+		exchange := util.NewTestExchange("app.events", internal.ExchangeTypes.FANOUT, []*internal.Binding{
+			{Id: uuid.New(), Queue: "orders"},
+			{Id: uuid.New(), Queue: "billing"},
+		})
+		exchange.DeduplicationWindow = 60
+		exchanges := map[string]*internal.Exchange{"app.events": exchange}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":         "Order placed",
+			"deduplicationId": "order-42",
+		})
+		originalResponse, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.events", messageBody)
+
+		response, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.events", messageBody)
+
+		util.AssertCreated(t, originalResponse)
+		util.AssertOk(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		jsonMessage := jsonResponse["message"].(map[string]interface{})
+		assert.Equal(t, util.JSONItemResponse(originalResponse)["message"].(map[string]interface{})["id"], jsonMessage["id"])
+		assert.Equal(t, true, jsonMessage["isDuplicate"])
+		assert.Equal(t, []interface{}{}, jsonResponse["routedTo"])
+		assert.Len(t, queues["orders"].GetMessages(), 1)
+		assert.Len(t, queues["billing"].GetMessages(), 1)
+	})
+
+	t.Run("Returns duplicate message when every routed queue drops it within its deduplication window", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders":  {Name: "orders", Durability: internal.Durability.DURABLE, DeduplicationWindow: 60},
+			"billing": {Name: "billing", Durability: internal.Durability.DURABLE, DeduplicationWindow: 60},
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.events": util.NewTestExchange("app.events", internal.ExchangeTypes.FANOUT, []*internal.Binding{
+				{Id: uuid.New(), Queue: "orders"},
+				{Id: uuid.New(), Queue: "billing"},
+			}),
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":         "Order placed",
+			"deduplicationId": "order-42",
+		})
+		originalResponse, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.events", messageBody)
+
+		response, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.events", messageBody)
+
+		util.AssertCreated(t, originalResponse)
+		util.AssertOk(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		jsonMessage := jsonResponse["message"].(map[string]interface{})
+		assert.Equal(t, util.JSONItemResponse(originalResponse)["message"].(map[string]interface{})["id"], jsonMessage["id"])
+		assert.Equal(t, true, jsonMessage["isDuplicate"])
+		assert.Equal(t, []interface{}{}, jsonResponse["routedTo"])
+		assert.Len(t, queues["orders"].GetMessages(), 1)
+		assert.Len(t, queues["billing"].GetMessages(), 1)
+	})
+
+	t.Run("Routes message to the queues not dropping it as a duplicate only", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders":  {Name: "orders", Durability: internal.Durability.DURABLE, DeduplicationWindow: 60},
+			"billing": util.NewTestQueueDurableWithoutMessages("billing"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.events": util.NewTestExchange("app.events", internal.ExchangeTypes.FANOUT, []*internal.Binding{
+				{Id: uuid.New(), Queue: "orders"},
+				{Id: uuid.New(), Queue: "billing"},
+			}),
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":         "Order placed",
+			"deduplicationId": "order-42",
+		})
+		_, _ = setupExchangeMessagePublishTest(t, queues, exchanges, "app.events", messageBody)
+
+		response, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.events", messageBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		jsonMessage := jsonResponse["message"].(map[string]interface{})
+		assert.Nil(t, jsonMessage["isDuplicate"])
+		assert.Equal(t, []interface{}{"billing"}, jsonResponse["routedTo"])
+		assert.Len(t, queues["orders"].GetMessages(), 1)
+		assert.Len(t, queues["billing"].GetMessages(), 2)
+	})
+
+	t.Run("Publishes message again once it failed to be routed", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders": util.NewTestQueueDurableWithoutMessages("orders"),
+		}
+		exchange := util.NewTestExchange("app.events", internal.ExchangeTypes.DIRECT, []*internal.Binding{
+			{Id: uuid.New(), Queue: "orders", RoutingKey: "order.placed"},
+		})
+		exchange.DeduplicationWindow = 60
+		exchanges := map[string]*internal.Exchange{"app.events": exchange}
+		unroutableBody, _ := json.Marshal(map[string]interface{}{
+			"payload":         "Order placed",
+			"routingKey":      "order.unknown",
+			"deduplicationId": "order-42",
+		})
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":         "Order placed",
+			"routingKey":      "order.placed",
+			"deduplicationId": "order-42",
+		})
+		unroutableResponse, _ := setupExchangeMessagePublishWithQueryTest(t, queues, exchanges, "app.events", "?mandatory=true", unroutableBody)
+
+		response, _ := setupExchangeMessagePublishWithQueryTest(t, queues, exchanges, "app.events", "?mandatory=true", messageBody)
+
+		util.AssertUnprocessableEntity(t, unroutableResponse, errs.MessageUnroutableErrorCode, "Message could not be routed to any Queue through Exchange 'app.events'")
+		util.AssertCreated(t, response)
+		assert.Len(t, queues["orders"].GetMessages(), 1)
+	})
+
+	t.Run("Publishes message to existing queues only when a binding points to a deleted queue", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"products": util.NewTestQueueDurableWithoutMessages("products"),
+			"all":      util.NewTestQueueDurableWithoutMessages("all"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.events": util.NewTestExchangeWithBindings("app.events", []*internal.Binding{
+				{Id: uuid.New(), Queue: "all", RoutingKey: "#"},
+				{Id: uuid.New(), Queue: "deleted", RoutingKey: "#"},
+				{Id: uuid.New(), Queue: "products", RoutingKey: "product.#"},
+			}),
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":    "Product created",
+			"routingKey": "product.created.v1",
+		})
+
+		response, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.events", messageBody)
+
+		util.AssertCreated(t, response)
+		assert.Len(t, queues["all"].GetMessages(), 1)
+		assert.Len(t, queues["products"].GetMessages(), 1)
+	})
+
+	t.Run("Publishes an independent copy of the message to each bound queue", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"billing":  util.NewTestQueueDurableWithoutMessages("billing"),
+			"shipping": util.NewTestQueueDurableWithoutMessages("shipping"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.events": util.NewTestExchange("app.events", internal.ExchangeTypes.FANOUT, []*internal.Binding{
+				{Id: uuid.New(), Queue: "billing"},
+				{Id: uuid.New(), Queue: "shipping"},
+			}),
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":    "Order placed",
+			"routingKey": "order.placed",
+			"headers":    map[string]string{"type": "order.placed"},
+		})
+
+		response, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.events", messageBody)
+
+		util.AssertCreated(t, response)
+		billingMessage := queues["billing"].GetMessages()[0]
+		shippingMessage := queues["shipping"].GetMessages()[0]
+		assert.NotSame(t, billingMessage, shippingMessage)
+		assert.NotEqual(t, billingMessage.Id, shippingMessage.Id)
+		assert.Equal(t, "Order placed", billingMessage.Payload)
+		assert.Equal(t, "Order placed", shippingMessage.Payload)
+		assert.Equal(t, map[string]string{"type": "order.placed"}, shippingMessage.Headers)
+	})
+
+	t.Run("Consumers on different queues are isolated from each other", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"billing":  util.NewTestQueueDurableWithoutMessages("billing"),
+			"shipping": util.NewTestQueueDurableWithoutMessages("shipping"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.events": util.NewTestExchange("app.events", internal.ExchangeTypes.FANOUT, []*internal.Binding{
+				{Id: uuid.New(), Queue: "billing"},
+				{Id: uuid.New(), Queue: "shipping"},
+			}),
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{"payload": "Order placed"})
+
+		response, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.events", messageBody)
+		util.AssertCreated(t, response)
+
+		billingMessage := queues["billing"].Dequeue()
+		assert.NotNil(t, billingMessage)
+		assert.True(t, billingMessage.IsProcessing())
+		assert.False(t, queues["shipping"].GetMessages()[0].IsProcessing())
+
+		assert.Nil(t, queues["billing"].Ack(billingMessage.Id))
+		assert.Len(t, queues["billing"].GetMessages(), 0)
+		assert.Len(t, queues["shipping"].GetMessages(), 1)
+
+		shippingMessage := queues["shipping"].Dequeue()
+		assert.NotNil(t, shippingMessage)
+		assert.Equal(t, 1, shippingMessage.DeliveryCount)
+		assert.NotNil(t, queues["shipping"].Ack(billingMessage.Id))
+		assert.Nil(t, queues["shipping"].Ack(shippingMessage.Id))
+	})
+
+	t.Run("Does not publish message when no binding routing key matches", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders": util.NewTestQueueDurableWithoutMessages("orders"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.events": util.NewTestExchangeWithBindings("app.events", []*internal.Binding{
+				{Id: uuid.New(), Queue: "orders", RoutingKey: "order.*"},
+			}),
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":    "Order created",
+			"routingKey": "order.created.v1",
+		})
+
+		response, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.events", messageBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, []interface{}{}, jsonResponse["routedTo"])
+		assert.Len(t, queues["orders"].GetMessages(), 0)
+	})
+
+	t.Run("Returns unprocessable entity when mandatory message matches no queue", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders": util.NewTestQueueDurableWithoutMessages("orders"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.events": util.NewTestExchangeWithBindings("app.events", []*internal.Binding{
+				{Id: uuid.New(), Queue: "orders", RoutingKey: "order.*"},
+				{Id: uuid.New(), Queue: "deleted", RoutingKey: "#"},
+			}),
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":    "Order created",
+			"routingKey": "order.created.v1",
+		})
+
+		response, _ := setupExchangeMessagePublishWithQueryTest(t, queues, exchanges, "app.events", "?mandatory=true", messageBody)
+
+		util.AssertUnprocessableEntity(t, response, errs.MessageUnroutableErrorCode, "Message could not be routed to any Queue through Exchange 'app.events'")
+		assert.Len(t, queues["orders"].GetMessages(), 0)
+	})
+
+	t.Run("Publishes mandatory message & lists the queues it was routed to", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"products": util.NewTestQueueDurableWithoutMessages("products"),
+			"orders":   util.NewTestQueueDurableWithoutMessages("orders"),
+			"all":      util.NewTestQueueDurableWithoutMessages("all"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.events": util.NewTestExchangeWithBindings("app.events", []*internal.Binding{
+				{Id: uuid.New(), Queue: "products", RoutingKey: "product.#"},
+				{Id: uuid.New(), Queue: "orders", RoutingKey: "order.*"},
+				{Id: uuid.New(), Queue: "all", RoutingKey: "#"},
+			}),
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":    "Product created",
+			"routingKey": "product.created.v1",
+		})
+
+		response, _ := setupExchangeMessagePublishWithQueryTest(t, queues, exchanges, "app.events", "?mandatory=true", messageBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		jsonMessage := jsonResponse["message"].(map[string]interface{})
+		assert.Equal(t, "Product created", jsonMessage["payload"])
+		assert.ElementsMatch(t, []interface{}{"products", "all"}, jsonResponse["routedTo"])
+	})
+
+	t.Run("Publishes unroutable message to the alternate exchange", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders":     util.NewTestQueueDurableWithoutMessages("orders"),
+			"unroutable": util.NewTestQueueDurableWithoutMessages("unroutable"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.events": {
+				Name:              "app.events",
+				Type:              internal.ExchangeTypes.TOPIC,
+				Bindings:          []*internal.Binding{{Id: uuid.New(), Queue: "orders", RoutingKey: "order.*"}},
+				AlternateExchange: "app.unroutable",
+			},
+			"app.unroutable": util.NewTestExchange("app.unroutable", internal.ExchangeTypes.FANOUT, []*internal.Binding{
+				{Id: uuid.New(), Queue: "unroutable"},
+			}),
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":    "Product created",
+			"routingKey": "product.created.v1",
+		})
+
+		response, _ := setupExchangeMessagePublishWithQueryTest(t, queues, exchanges, "app.events", "?mandatory=true", messageBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, []interface{}{"unroutable"}, jsonResponse["routedTo"])
+		assert.Len(t, queues["orders"].GetMessages(), 0)
+		assert.Len(t, queues["unroutable"].GetMessages(), 1)
+		assert.Equal(t, "app.events", queues["unroutable"].GetMessages()[0].Exchange)
+	})
+
+	t.Run("Does not publish routable message to the alternate exchange", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders":     util.NewTestQueueDurableWithoutMessages("orders"),
+			"unroutable": util.NewTestQueueDurableWithoutMessages("unroutable"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.events": {
+				Name:              "app.events",
+				Type:              internal.ExchangeTypes.TOPIC,
+				Bindings:          []*internal.Binding{{Id: uuid.New(), Queue: "orders", RoutingKey: "order.*"}},
+				AlternateExchange: "app.unroutable",
+			},
+			"app.unroutable": util.NewTestExchange("app.unroutable", internal.ExchangeTypes.FANOUT, []*internal.Binding{
+				{Id: uuid.New(), Queue: "unroutable"},
+			}),
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":    "Order created",
+			"routingKey": "order.created",
+		})
+
+		response, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.events", messageBody)
+
+		util.AssertCreated(t, response)
+		assert.Len(t, queues["orders"].GetMessages(), 1)
+		assert.Len(t, queues["unroutable"].GetMessages(), 0)
+	})
+
+	t.Run("Stops following alternate exchanges once they loop", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders": util.NewTestQueueDurableWithoutMessages("orders"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.events": {
+				Name:              "app.events",
+				Type:              internal.ExchangeTypes.TOPIC,
+				Bindings:          []*internal.Binding{{Id: uuid.New(), Queue: "orders", RoutingKey: "order.*"}},
+				AlternateExchange: "app.fallback",
+			},
+			"app.fallback": {
+				Name:              "app.fallback",
+				Type:              internal.ExchangeTypes.DIRECT,
+				Bindings:          []*internal.Binding{},
+				AlternateExchange: "app.events",
+			},
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":    "Product created",
+			"routingKey": "product.created.v1",
+		})
+
+		response, _ := setupExchangeMessagePublishWithQueryTest(t, queues, exchanges, "app.events", "?mandatory=true", messageBody)
+
+		util.AssertUnprocessableEntity(t, response, errs.MessageUnroutableErrorCode, "Message could not be routed to any Queue through Exchange 'app.events'")
+		assert.Len(t, queues["orders"].GetMessages(), 0)
+	})
+
+	t.Run("Publishes message through exchange to exchange bindings", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"products": util.NewTestQueueDurableWithoutMessages("products"),
+			"audit":    util.NewTestQueueDurableWithoutMessages("audit"),
+			"orders":   util.NewTestQueueDurableWithoutMessages("orders"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.internal": util.NewTestExchangeWithBindings("app.internal", []*internal.Binding{
+				{Id: uuid.New(), Exchange: "product", RoutingKey: "product.#"},
+				{Id: uuid.New(), Queue: "orders", RoutingKey: "order.#"},
+			}),
+			"product": util.NewTestExchange("product", internal.ExchangeTypes.FANOUT, []*internal.Binding{
+				{Id: uuid.New(), Queue: "products"},
+				{Id: uuid.New(), Exchange: "product.audit"},
+			}),
+			"product.audit": util.NewTestExchange("product.audit", internal.ExchangeTypes.DIRECT, []*internal.Binding{
+				{Id: uuid.New(), Queue: "audit", RoutingKey: "product.created"},
+			}),
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":    "Product created",
+			"routingKey": "product.created",
+		})
+
+		response, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.internal", messageBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.ElementsMatch(t, []interface{}{"products", "audit"}, jsonResponse["routedTo"])
+		assert.Len(t, queues["products"].GetMessages(), 1)
+		assert.Len(t, queues["audit"].GetMessages(), 1)
+		assert.Len(t, queues["orders"].GetMessages(), 0)
+		assert.Equal(t, "app.internal", queues["audit"].GetMessages()[0].Exchange)
+	})
+
+	t.Run("Publishes message once per queue when exchange bindings form a cycle", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.a": util.NewTestExchange("app.a", internal.ExchangeTypes.FANOUT, []*internal.Binding{
+				{Id: uuid.New(), Exchange: "app.b"},
+				{Id: uuid.New(), Queue: "events"},
+			}),
+			"app.b": util.NewTestExchange("app.b", internal.ExchangeTypes.FANOUT, []*internal.Binding{
+				{Id: uuid.New(), Exchange: "app.a"},
+				{Id: uuid.New(), Queue: "events"},
+			}),
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{"payload": "Hello"})
+
+		response, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.a", messageBody)
+
+		util.AssertCreated(t, response)
+		assert.Len(t, queues["events"].GetMessages(), 1)
+	})
+
+	t.Run("Returns bad request when mandatory is invalid", func(t *testing.T) {
+		messageBody, _ := json.Marshal(map[string]interface{}{"payload": "Hello"})
+
+		response, _ := setupExchangeMessagePublishWithQueryTest(t, map[string]*internal.Queue{}, exchanges, "app.internal", "?mandatory=maybe", messageBody)
+
+		util.AssertBadRequest(t, response, errs.ParamInvalidErrorCode, "Invalid value 'maybe'. Must be one of: true false")
+	})
+
+	t.Run("Publishes message according to the exchange type", func(t *testing.T) {
+		testCases := []struct {
+			name           string
+			exchangeType   internal.ExchangeType
+			bindings       []*internal.Binding
+			message        map[string]interface{}
+			expectedQueues []string
+		}{
+			{
+				name:         "direct",
+				exchangeType: internal.ExchangeTypes.DIRECT,
+				bindings: []*internal.Binding{
+					{Id: uuid.New(), Queue: "q1", RoutingKey: "product.created"},
+					{Id: uuid.New(), Queue: "q2", RoutingKey: "product.*"},
+				},
+				message:        map[string]interface{}{"payload": "Hello", "routingKey": "product.created"},
+				expectedQueues: []string{"q1"},
+			},
+			{
+				name:         "fanout",
+				exchangeType: internal.ExchangeTypes.FANOUT,
+				bindings: []*internal.Binding{
+					{Id: uuid.New(), Queue: "q1", RoutingKey: "product.created"},
+					{Id: uuid.New(), Queue: "q2", RoutingKey: "order.created"},
+				},
+				message:        map[string]interface{}{"payload": "Hello", "routingKey": "whatever"},
+				expectedQueues: []string{"q1", "q2"},
+			},
+			{
+				name:         "topic",
+				exchangeType: internal.ExchangeTypes.TOPIC,
+				bindings: []*internal.Binding{
+					{Id: uuid.New(), Queue: "q1", RoutingKey: "product.created"},
+					{Id: uuid.New(), Queue: "q2", RoutingKey: "product.*"},
+				},
+				message:        map[string]interface{}{"payload": "Hello", "routingKey": "product.published"},
+				expectedQueues: []string{"q2"},
+			},
+			{
+				name:         "headers (all)",
+				exchangeType: internal.ExchangeTypes.HEADERS,
+				bindings: []*internal.Binding{
+					{Id: uuid.New(), Queue: "q1", Arguments: map[string]string{"x-match": "all", "type": "product", "version": "v1"}},
+					{Id: uuid.New(), Queue: "q2", Arguments: map[string]string{"type": "product", "version": "v2"}},
+				},
+				message: map[string]interface{}{
+					"payload": "Hello",
+					"headers": map[string]string{"type": "product", "version": "v1"},
+				},
+				expectedQueues: []string{"q1"},
+			},
+			{
+				name:         "headers (any)",
+				exchangeType: internal.ExchangeTypes.HEADERS,
+				bindings: []*internal.Binding{
+					{Id: uuid.New(), Queue: "q1", Arguments: map[string]string{"x-match": "any", "type": "order", "version": "v1"}},
+					{Id: uuid.New(), Queue: "q2", Arguments: map[string]string{"x-match": "any", "type": "order", "version": "v2"}},
+				},
+				message: map[string]interface{}{
+					"payload": "Hello",
+					"headers": map[string]string{"type": "product", "version": "v1"},
+				},
+				expectedQueues: []string{"q1"},
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				queues := map[string]*internal.Queue{
+					"q1": util.NewTestQueueDurableWithoutMessages("q1"),
+					"q2": util.NewTestQueueDurableWithoutMessages("q2"),
+				}
+				exchanges := map[string]*internal.Exchange{
+					"app.events": util.NewTestExchange("app.events", tc.exchangeType, tc.bindings),
+				}
+				messageBody, _ := json.Marshal(tc.message)
+
+				response, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.events", messageBody)
+
+				util.AssertCreated(t, response)
+				for name, queue := range queues {
+					if slices.Contains(tc.expectedQueues, name) {
+						assert.Len(t, queue.GetMessages(), 1, "Queue '%s' should receive the message", name)
+					} else {
+						assert.Empty(t, queue.GetMessages(), "Queue '%s' should not receive the message", name)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("Publishes raw body routed by its routing key header", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"products": util.NewTestQueueDurableWithoutMessages("products"),
+			"orders":   util.NewTestQueueDurableWithoutMessages("orders"),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.events": util.NewTestExchangeWithBindings("app.events", []*internal.Binding{
+				{Id: uuid.New(), Queue: "products", RoutingKey: "product.#"},
+				{Id: uuid.New(), Queue: "orders", RoutingKey: "order.#"},
+			}),
+		}
+		body := []byte{0x1f, 0x8b, 0x08, 0x00, 0xff}
+
+		response, _ := setupExchangeMessagePublishRawTest(t, queues, exchanges, "app.events", body, map[string]string{
+			"Content-Type":  "application/gzip",
+			"X-Routing-Key": "product.created",
+		})
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		jsonMessage := jsonResponse["message"].(map[string]interface{})
+		assert.Equal(t, base64.StdEncoding.EncodeToString(body), jsonMessage["payload"])
+		assert.Equal(t, "base64", jsonMessage["payloadEncoding"])
+		assert.Len(t, queues["products"].GetMessages(), 1)
+		assert.Len(t, queues["orders"].GetMessages(), 0)
+		assert.Equal(t, string(body), queues["products"].GetMessages()[0].Payload)
+		assert.Equal(t, "application/gzip", queues["products"].GetMessages()[0].ContentType)
 	})
 
 	t.Run("Returns validation error when no message payload supplied", func(t *testing.T) {
@@ -80,7 +687,7 @@ func TestHandleExchangeMessagePublish(t *testing.T) {
 		response, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.internal", messageBody)
 
 		util.AssertValidationErrors(t, response, []errs.ValidationError{
-			{"payload", "This field is required"},
+			{Field: "payload", Message: "This field is required"},
 		})
 	})
 
@@ -92,7 +699,7 @@ func TestHandleExchangeMessagePublish(t *testing.T) {
 		response, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.internal", messageBody)
 
 		util.AssertValidationErrors(t, response, []errs.ValidationError{
-			{"payload", "This field is required"},
+			{Field: "payload", Message: "This field is required"},
 		})
 	})
 
@@ -104,7 +711,7 @@ func TestHandleExchangeMessagePublish(t *testing.T) {
 		response, _ := setupExchangeMessagePublishTest(t, queues, exchanges, "app.internal", messageBody)
 
 		util.AssertValidationErrors(t, response, []errs.ValidationError{
-			{"payload", "This field is required"},
+			{Field: "payload", Message: "This field is required"},
 		})
 	})
 
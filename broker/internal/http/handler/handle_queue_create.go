@@ -35,7 +35,11 @@ func HandleQueueCreate(queueRepository storage.QueueRepository, validate *valida
 			return
 		}
 
-		queueRepository.StoreQueue(&queue)
+		storeErr := queueRepository.StoreQueue(&queue)
+		if storeErr != nil {
+			util.Respond(w, storeErr, util.HttpStatusCodeFromAppError(storeErr))
+			return
+		}
 
 		util.Respond(w, &queue, http.StatusCreated)
 	}
@@ -10,14 +10,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/melyouz/risala/broker/internal"
 	"github.com/melyouz/risala/broker/internal/errs"
-	httputil "github.com/melyouz/risala/broker/internal/http/util"
 	"github.com/melyouz/risala/broker/internal/storage"
 	"github.com/melyouz/risala/broker/internal/testing/util"
+	"github.com/melyouz/risala/broker/internal/validation"
 )
 
 func setupQueueCreateTest(t *testing.T, queues map[string]*internal.Queue, body map[string]interface{}) (*httptest.ResponseRecorder, *http.Request) {
@@ -28,7 +29,7 @@ func setupQueueCreateTest(t *testing.T, queues map[string]*internal.Queue, body
 	request := httptest.NewRequest(http.MethodPost, util.ApiV1BasePath+"/queues", bytes.NewReader(requestBody))
 	response := httptest.NewRecorder()
 
-	HandleQueueCreate(queueRepository, httputil.NewJSONValidator())(response, request)
+	HandleQueueCreate(queueRepository, validation.NewJSONValidator())(response, request)
 
 	return response, request
 }
@@ -77,7 +78,218 @@ func TestHandleQueueCreate(t *testing.T) {
 		response, _ := setupQueueCreateTest(t, queues, queueBody)
 
 		util.AssertValidationErrors(t, response, []errs.ValidationError{
-			{"durability", "Invalid value 'whatever'. Must be one of: durable transient"},
+			{Field: "durability", Message: "Invalid value 'whatever'. Must be one of: durable transient"},
+		})
+	})
+
+	t.Run("Creates queue with visibility timeout", func(t *testing.T) {
+
+		queues := map[string]*internal.Queue{}
+		queueBody := map[string]interface{}{
+			"name":              "testQueueName",
+			"durability":        internal.Durability.DURABLE.String(),
+			"visibilityTimeout": 60,
+		}
+
+		response, _ := setupQueueCreateTest(t, queues, queueBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, float64(60), jsonResponse["visibilityTimeout"])
+		assert.Equal(t, time.Minute, queues["testQueueName"].GetVisibilityTimeout())
+	})
+
+	t.Run("Creates queue with max deliveries", func(t *testing.T) {
+
+		queues := map[string]*internal.Queue{}
+		queueBody := map[string]interface{}{
+			"name":          "testQueueName",
+			"durability":    internal.Durability.DURABLE.String(),
+			"maxDeliveries": 5,
+		}
+
+		response, _ := setupQueueCreateTest(t, queues, queueBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, float64(5), jsonResponse["maxDeliveries"])
+	})
+
+	t.Run("Creates queue with message TTL", func(t *testing.T) {
+
+		queues := map[string]*internal.Queue{}
+		queueBody := map[string]interface{}{
+			"name":              "testQueueName",
+			"durability":        internal.Durability.DURABLE.String(),
+			"messageTtl":        3600,
+			"deadLetterExpired": true,
+		}
+
+		response, _ := setupQueueCreateTest(t, queues, queueBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, float64(3600), jsonResponse["messageTtl"])
+		assert.Equal(t, true, jsonResponse["deadLetterExpired"])
+	})
+
+	t.Run("Returns validation error when queue message TTL is negative", func(t *testing.T) {
+
+		queues := map[string]*internal.Queue{}
+		queueBody := map[string]interface{}{
+			"name":       "testQueueName",
+			"durability": internal.Durability.DURABLE.String(),
+			"messageTtl": -1,
+		}
+
+		response, _ := setupQueueCreateTest(t, queues, queueBody)
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "messageTtl", Message: "Invalid value '-1'. Must be greater than or equal to 0"},
+		})
+	})
+
+	t.Run("Creates queue with length limits", func(t *testing.T) {
+
+		queues := map[string]*internal.Queue{}
+		queueBody := map[string]interface{}{
+			"name":       "testQueueName",
+			"durability": internal.Durability.DURABLE.String(),
+			"maxLength":  100,
+			"maxBytes":   1024,
+			"overflow":   internal.OverflowPolicies.REJECT_PUBLISH.String(),
+		}
+
+		response, _ := setupQueueCreateTest(t, queues, queueBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, float64(100), jsonResponse["maxLength"])
+		assert.Equal(t, float64(1024), jsonResponse["maxBytes"])
+		assert.Equal(t, "reject-publish", jsonResponse["overflow"])
+	})
+
+	t.Run("Returns validation errors when queue length limits are invalid", func(t *testing.T) {
+
+		queues := map[string]*internal.Queue{}
+		queueBody := map[string]interface{}{
+			"name":       "testQueueName",
+			"durability": internal.Durability.DURABLE.String(),
+			"maxLength":  -1,
+			"maxBytes":   -1,
+			"overflow":   "drop-tail",
+		}
+
+		response, _ := setupQueueCreateTest(t, queues, queueBody)
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "maxLength", Message: "Invalid value '-1'. Must be greater than or equal to 0"},
+			{Field: "maxBytes", Message: "Invalid value '-1'. Must be greater than or equal to 0"},
+			{Field: "overflow", Message: "Invalid value 'drop-tail'. Must be one of: drop-head reject-publish dead-letter-head"},
+		})
+	})
+
+	t.Run("Creates queue with max priority", func(t *testing.T) {
+
+		queues := map[string]*internal.Queue{}
+		queueBody := map[string]interface{}{
+			"name":        "testQueueName",
+			"durability":  internal.Durability.DURABLE.String(),
+			"maxPriority": 10,
+		}
+
+		response, _ := setupQueueCreateTest(t, queues, queueBody)
+
+		util.AssertCreated(t, response)
+		assert.Equal(t, float64(10), util.JSONItemResponse(response)["maxPriority"])
+	})
+
+	t.Run("Returns validation error when queue max priority is out of range", func(t *testing.T) {
+
+		queues := map[string]*internal.Queue{}
+		queueBody := map[string]interface{}{
+			"name":        "testQueueName",
+			"durability":  internal.Durability.DURABLE.String(),
+			"maxPriority": 256,
+		}
+
+		response, _ := setupQueueCreateTest(t, queues, queueBody)
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "maxPriority", Message: "Invalid value '256'. Must be less than or equal to 255"},
+		})
+	})
+
+	t.Run("Creates queue with deduplication window", func(t *testing.T) {
+
+		queues := map[string]*internal.Queue{}
+		queueBody := map[string]interface{}{
+			"name":                "testQueueName",
+			"durability":          internal.Durability.DURABLE.String(),
+			"deduplicationWindow": 300,
+		}
+
+		response, _ := setupQueueCreateTest(t, queues, queueBody)
+
+		util.AssertCreated(t, response)
+		assert.Equal(t, float64(300), util.JSONItemResponse(response)["deduplicationWindow"])
+	})
+
+	t.Run("Creates queue with dead-letter exchange", func(t *testing.T) {
+
+		queues := map[string]*internal.Queue{}
+		queueBody := map[string]interface{}{
+			"name":                 "testQueueName",
+			"durability":           internal.Durability.DURABLE.String(),
+			"deadLetterExchange":   "app.dead-letter",
+			"deadLetterRoutingKey": "dead.testQueueName",
+		}
+
+		response, _ := setupQueueCreateTest(t, queues, queueBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, "app.dead-letter", jsonResponse["deadLetterExchange"])
+		assert.Equal(t, "dead.testQueueName", jsonResponse["deadLetterRoutingKey"])
+	})
+
+	t.Run("Returns validation error when queue max deliveries is negative", func(t *testing.T) {
+
+		queues := map[string]*internal.Queue{}
+		queueBody := map[string]interface{}{
+			"name":          "testQueueName",
+			"durability":    internal.Durability.DURABLE.String(),
+			"maxDeliveries": -1,
+		}
+
+		response, _ := setupQueueCreateTest(t, queues, queueBody)
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "maxDeliveries", Message: "Invalid value '-1'. Must be greater than or equal to 0"},
+		})
+	})
+
+	t.Run("Returns validation error when queue visibility timeout is out of range", func(t *testing.T) {
+
+		queues := map[string]*internal.Queue{}
+		queueBody := map[string]interface{}{
+			"name":              "testQueueName",
+			"durability":        internal.Durability.DURABLE.String(),
+			"visibilityTimeout": -1,
+		}
+
+		response, _ := setupQueueCreateTest(t, queues, queueBody)
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "visibilityTimeout", Message: "Invalid value '-1'. Must be greater than or equal to 0"},
+		})
+
+		queueBody["visibilityTimeout"] = 43201
+
+		response, _ = setupQueueCreateTest(t, queues, queueBody)
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "visibilityTimeout", Message: "Invalid value '43201'. Must be less than or equal to 43200"},
 		})
 	})
 
@@ -91,7 +303,7 @@ func TestHandleQueueCreate(t *testing.T) {
 		response, _ := setupQueueCreateTest(t, queues, queueBody)
 
 		util.AssertValidationErrors(t, response, []errs.ValidationError{
-			{"name", "This field is required"},
+			{Field: "name", Message: "This field is required"},
 		})
 	})
 
@@ -105,7 +317,7 @@ func TestHandleQueueCreate(t *testing.T) {
 		response, _ := setupQueueCreateTest(t, queues, queueBody)
 
 		util.AssertValidationErrors(t, response, []errs.ValidationError{
-			{"durability", "This field is required"},
+			{Field: "durability", Message: "This field is required"},
 		})
 	})
 
@@ -119,8 +331,8 @@ func TestHandleQueueCreate(t *testing.T) {
 		response, _ := setupQueueCreateTest(t, queues, queueBody)
 
 		util.AssertValidationErrors(t, response, []errs.ValidationError{
-			{"name", "This field is required"},
-			{"durability", "This field is required"},
+			{Field: "name", Message: "This field is required"},
+			{Field: "durability", Message: "This field is required"},
 		})
 	})
 
@@ -56,15 +56,15 @@ func TestHandleQueueMessageAck(t *testing.T) {
 			{Id: uuid.New(), Payload: "Message 4"},
 			{Id: uuid.New(), Payload: "Message 5"},
 		}
-		queues["events"].Messages = messages
+		queues["events"].RestoreMessages(messages)
 		initialMessageCount := len(messages)
 		messageId := messages[0].Id
 
 		response, _ := setupQueueMessageAckTest(t, queues, "events", messageId)
 
 		util.AssertNoContent(t, response)
-		assert.Len(t, queues["events"].Messages, initialMessageCount-1)
-		assert.Len(t, queues[internal.DeadLetterQueueName].Messages, 0)
+		assert.Len(t, queues["events"].GetMessages(), initialMessageCount-1)
+		assert.Len(t, queues[internal.DeadLetterQueueName].GetMessages(), 0)
 	})
 
 	t.Run("Returns not found when message is not being processed", func(t *testing.T) {
@@ -72,7 +72,7 @@ func TestHandleQueueMessageAck(t *testing.T) {
 			{Id: uuid.New(), Payload: "Message 1"},
 			{Id: uuid.New(), Payload: "Message 2", Processing: true},
 		}
-		queues["events"].Messages = messages
+		queues["events"].RestoreMessages(messages)
 		messageId := messages[0].Id
 
 		response, _ := setupQueueMessageAckTest(t, queues, "events", messageId)
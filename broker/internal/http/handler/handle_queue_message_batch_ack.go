@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+	"github.com/melyouz/risala/broker/internal/http/util"
+	"github.com/melyouz/risala/broker/internal/storage"
+)
+
+type messageIdBatch struct {
+	MessageIds []string `json:"messageIds" validate:"required,min=1,dive,uuid"`
+}
+
+type messageBatchResult struct {
+	MessageId string        `json:"messageId,omitempty"`
+	Success   bool          `json:"success"`
+	Error     errs.AppError `json:"error,omitempty"`
+	RoutedTo  []string      `json:"routedTo,omitempty"`
+	Duplicate bool          `json:"isDuplicate,omitempty"`
+}
+
+func HandleQueueMessageBatchAck(queueRepository storage.QueueRepository, validate *validator.Validate) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var batch messageIdBatch
+		util.Decode(r, &batch)
+
+		var vErrors validator.ValidationErrors
+		if errors.As(validate.Struct(&batch), &vErrors) {
+			util.Respond(w, errs.NewValidationError(vErrors), http.StatusUnprocessableEntity)
+			return
+		}
+		if len(batch.MessageIds) > internal.MaxBatchSize {
+			sizeErr := errs.NewParamInvalidError("messageIds", fmt.Sprintf("Must contain between 1 and %d message ids", internal.MaxBatchSize))
+			util.Respond(w, sizeErr, util.HttpStatusCodeFromAppError(sizeErr))
+			return
+		}
+
+		queueName := chi.URLParam(r, "queueName")
+		queue, queueErr := queueRepository.GetQueue(queueName)
+		if queueErr != nil {
+			util.Respond(w, queueErr, util.HttpStatusCodeFromAppError(queueErr))
+			return
+		}
+
+		results := make([]messageBatchResult, len(batch.MessageIds))
+		for i, messageId := range batch.MessageIds {
+			ackErr := queue.Ack(uuid.MustParse(messageId))
+			results[i] = messageBatchResult{MessageId: messageId, Success: ackErr == nil, Error: ackErr}
+		}
+
+		util.Respond(w, results, http.StatusOK)
+	}
+}
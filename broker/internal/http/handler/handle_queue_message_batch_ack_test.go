@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+	"github.com/melyouz/risala/broker/internal/storage"
+	"github.com/melyouz/risala/broker/internal/testing/util"
+	"github.com/melyouz/risala/broker/internal/validation"
+)
+
+func setupQueueMessageBatchAckTest(t *testing.T, queues map[string]*internal.Queue, queueName string, body map[string]interface{}) (*httptest.ResponseRecorder, *http.Request) {
+	t.Helper()
+
+	queueRepository := storage.NewInMemoryQueueRepository(queues)
+
+	requestBody, _ := json.Marshal(body)
+	path := fmt.Sprintf("%s/queues/%s/messages/batch/ack", util.ApiV1BasePath, queueName)
+	request := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(requestBody))
+	response := httptest.NewRecorder()
+
+	routerCtx := chi.NewRouteContext()
+	routerCtx.URLParams.Add("queueName", queueName)
+	request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routerCtx))
+
+	HandleQueueMessageBatchAck(queueRepository, validation.NewJSONValidator())(response, request)
+
+	return response, request
+}
+
+func TestHandleQueueMessageBatchAck(t *testing.T) {
+
+	queues := map[string]*internal.Queue{
+		"events": util.NewTestQueueDurableWithoutMessages("events"),
+	}
+
+	t.Run("Acknowledges messages & reports result per message", func(t *testing.T) {
+		messages := []*internal.Message{
+			{Id: uuid.New(), Payload: "Message 1", Processing: true},
+			{Id: uuid.New(), Payload: "Message 2", Processing: true},
+			{Id: uuid.New(), Payload: "Message 3"},
+		}
+		queues["events"].RestoreMessages(messages)
+		messageIds := []string{messages[0].Id.String(), messages[1].Id.String(), messages[2].Id.String()}
+		remainingMessage := messages[2]
+
+		response, _ := setupQueueMessageBatchAckTest(t, queues, "events", map[string]interface{}{
+			"messageIds": messageIds,
+		})
+
+		util.AssertOk(t, response)
+		jsonResponse := util.JSONCollectionResponse(response)
+		assert.Len(t, jsonResponse, 3)
+		assert.Equal(t, map[string]interface{}{"messageId": messageIds[0], "success": true}, jsonResponse[0])
+		assert.Equal(t, map[string]interface{}{"messageId": messageIds[1], "success": true}, jsonResponse[1])
+		assert.Equal(t, messageIds[2], jsonResponse[2]["messageId"])
+		assert.Equal(t, false, jsonResponse[2]["success"])
+		assert.Equal(t, map[string]interface{}{
+			"code":    errs.MessageNotFoundErrorCode,
+			"message": fmt.Sprintf("Message '%s' not found", messageIds[2]),
+		}, jsonResponse[2]["error"])
+		assert.Equal(t, []*internal.Message{remainingMessage}, queues["events"].GetMessages())
+	})
+
+	t.Run("Returns validation error when no message ids supplied", func(t *testing.T) {
+		response, _ := setupQueueMessageBatchAckTest(t, queues, "events", map[string]interface{}{
+			"messageIds": []string{},
+		})
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "messageIds", Message: "Must contain at least 1 items"},
+		})
+	})
+
+	t.Run("Returns validation error when message id is invalid", func(t *testing.T) {
+		response, _ := setupQueueMessageBatchAckTest(t, queues, "events", map[string]interface{}{
+			"messageIds": []string{"whatever"},
+		})
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "messageIds[0]", Message: "Invalid value 'whatever'. Must be a UUID"},
+		})
+	})
+
+	t.Run("Returns bad request when too many message ids supplied", func(t *testing.T) {
+		messageIds := make([]string, internal.MaxBatchSize+1)
+		for i := range messageIds {
+			messageIds[i] = uuid.New().String()
+		}
+
+		response, _ := setupQueueMessageBatchAckTest(t, queues, "events", map[string]interface{}{
+			"messageIds": messageIds,
+		})
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, errs.ParamInvalidErrorCode, jsonResponse["code"])
+		assert.Equal(t, "messageIds", jsonResponse["param"])
+	})
+
+	t.Run("Returns not found when queue does not exist", func(t *testing.T) {
+		response, _ := setupQueueMessageBatchAckTest(t, queues, "nonExistingQueueName", map[string]interface{}{
+			"messageIds": []string{uuid.New().String()},
+		})
+
+		util.AssertNotFound(t, response, "QUEUE_NOT_FOUND", "Queue 'nonExistingQueueName' not found")
+	})
+}
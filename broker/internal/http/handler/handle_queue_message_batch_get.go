@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+	"github.com/melyouz/risala/broker/internal/http/util"
+	"github.com/melyouz/risala/broker/internal/storage"
+)
+
+func HandleQueueMessageBatchGet(queueRepository storage.QueueRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limitParamName := "limit"
+		limit, paramErr := util.IntQueryParam(r, limitParamName, 1)
+		if paramErr == nil && (limit < 1 || limit > internal.MaxBatchSize) {
+			paramErr = errs.NewParamInvalidError(limitParamName, fmt.Sprintf("Must be between 1 and %d", internal.MaxBatchSize))
+		}
+		if paramErr != nil {
+			util.Respond(w, paramErr, util.HttpStatusCodeFromAppError(paramErr))
+			return
+		}
+
+		visibilityTimeoutParamName := "visibilityTimeout"
+		visibilityTimeout, paramErr := util.DurationQueryParam(r, visibilityTimeoutParamName)
+		if paramErr == nil && visibilityTimeout > internal.MaxVisibilityTimeout {
+			paramErr = errs.NewParamInvalidError(visibilityTimeoutParamName, fmt.Sprintf("Must not exceed %s", internal.MaxVisibilityTimeout))
+		}
+		if paramErr != nil {
+			util.Respond(w, paramErr, util.HttpStatusCodeFromAppError(paramErr))
+			return
+		}
+
+		waitParamName := "wait"
+		wait, paramErr := util.DurationQueryParam(r, waitParamName)
+		if paramErr == nil && wait > internal.MaxDequeueWait {
+			paramErr = errs.NewParamInvalidError(waitParamName, fmt.Sprintf("Must not exceed %s", internal.MaxDequeueWait))
+		}
+		if paramErr != nil {
+			util.Respond(w, paramErr, util.HttpStatusCodeFromAppError(paramErr))
+			return
+		}
+
+		queueName := chi.URLParam(r, "queueName")
+		queue, queueErr := queueRepository.GetQueue(queueName)
+		if queueErr != nil {
+			util.Respond(w, queueErr, util.HttpStatusCodeFromAppError(queueErr))
+			return
+		}
+
+		messages := queue.DequeueBatchWithWait(r.Context(), limit, visibilityTimeout, wait)
+
+		util.Respond(w, messages, http.StatusOK)
+	}
+}
@@ -0,0 +1,125 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+	"github.com/melyouz/risala/broker/internal/storage"
+	"github.com/melyouz/risala/broker/internal/testing/util"
+)
+
+func setupQueueMessageBatchGetTest(t *testing.T, queues map[string]*internal.Queue, queueName string, query string) (*httptest.ResponseRecorder, *http.Request) {
+	t.Helper()
+
+	queueRepository := storage.NewInMemoryQueueRepository(queues)
+
+	path := fmt.Sprintf("%s/queues/%s/messages/batch/get?%s", util.ApiV1BasePath, queueName, query)
+	request := httptest.NewRequest(http.MethodPost, path, nil)
+	response := httptest.NewRecorder()
+
+	routerCtx := chi.NewRouteContext()
+	routerCtx.URLParams.Add("queueName", queueName)
+	request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routerCtx))
+
+	HandleQueueMessageBatchGet(queueRepository)(response, request)
+
+	return response, request
+}
+
+func TestHandleQueueMessageBatchGet(t *testing.T) {
+
+	queues := map[string]*internal.Queue{
+		"events": util.NewTestQueueDurableWithoutMessages("events"),
+	}
+
+	t.Run("Marks as processing & returns up to limit available messages", func(t *testing.T) {
+		messages := []*internal.Message{
+			{Id: uuid.New(), Payload: "Message 1", Processing: true},
+			{Id: uuid.New(), Payload: "Message 2"},
+			{Id: uuid.New(), Payload: "Message 3"},
+			{Id: uuid.New(), Payload: "Message 4"},
+		}
+		queues["events"].RestoreMessages(messages)
+
+		response, _ := setupQueueMessageBatchGetTest(t, queues, "events", "limit=2")
+
+		util.AssertOk(t, response)
+		jsonResponse := util.JSONCollectionResponse(response)
+		assert.Len(t, jsonResponse, 2)
+		assert.Equal(t, "Message 2", jsonResponse[0]["payload"])
+		assert.Equal(t, "Message 3", jsonResponse[1]["payload"])
+		assert.Len(t, queues["events"].GetMessages(), 4)
+		assert.True(t, messages[1].IsProcessing())
+		assert.True(t, messages[2].IsProcessing())
+		assert.False(t, messages[3].IsProcessing())
+	})
+
+	t.Run("Returns one message when no limit supplied", func(t *testing.T) {
+		queues["events"].RestoreMessages([]*internal.Message{
+			{Id: uuid.New(), Payload: "Message 1"},
+			{Id: uuid.New(), Payload: "Message 2"},
+		})
+
+		response, _ := setupQueueMessageBatchGetTest(t, queues, "events", "")
+
+		util.AssertOk(t, response)
+		assert.Len(t, util.JSONCollectionResponse(response), 1)
+	})
+
+	t.Run("Returns empty list when no messages available", func(t *testing.T) {
+		queues["events"].RestoreMessages([]*internal.Message{
+			{Id: uuid.New(), Payload: "Message 1", Processing: true},
+		})
+
+		response, _ := setupQueueMessageBatchGetTest(t, queues, "events", "limit=10")
+
+		util.AssertOk(t, response)
+		assert.Len(t, util.JSONCollectionResponse(response), 0)
+	})
+
+	t.Run("Waits for messages to be enqueued when wait supplied", func(t *testing.T) {
+		queues["events"].RestoreMessages([]*internal.Message{})
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_ = queues["events"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 1"})
+		}()
+
+		response, _ := setupQueueMessageBatchGetTest(t, queues, "events", "limit=10&wait=5s")
+
+		util.AssertOk(t, response)
+		jsonResponse := util.JSONCollectionResponse(response)
+		assert.Len(t, jsonResponse, 1)
+		assert.Equal(t, "Message 1", jsonResponse[0]["payload"])
+	})
+
+	t.Run("Returns bad request when limit is invalid", func(t *testing.T) {
+		for _, limit := range []string{"whatever", "-1", "0", "101"} {
+			response, _ := setupQueueMessageBatchGetTest(t, queues, "events", "limit="+limit)
+
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+			jsonResponse := util.JSONItemResponse(response)
+			assert.Equal(t, errs.ParamInvalidErrorCode, jsonResponse["code"])
+			assert.Equal(t, "limit", jsonResponse["param"])
+		}
+	})
+
+	t.Run("Returns not found when queue does not exist", func(t *testing.T) {
+		response, _ := setupQueueMessageBatchGetTest(t, queues, "nonExistingQueueName", "")
+
+		util.AssertNotFound(t, response, "QUEUE_NOT_FOUND", "Queue 'nonExistingQueueName' not found")
+	})
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+	"github.com/melyouz/risala/broker/internal/http/util"
+	"github.com/melyouz/risala/broker/internal/routing"
+	"github.com/melyouz/risala/broker/internal/storage"
+)
+
+func HandleQueueMessageBatchNack(
+	queueRepository storage.QueueRepository,
+	exchangeRepository storage.ExchangeRepository,
+	validate *validator.Validate,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requeue, delay, paramErr := nackQueryParams(r)
+		if paramErr != nil {
+			util.Respond(w, paramErr, util.HttpStatusCodeFromAppError(paramErr))
+			return
+		}
+
+		var batch messageIdBatch
+		util.Decode(r, &batch)
+
+		var vErrors validator.ValidationErrors
+		if errors.As(validate.Struct(&batch), &vErrors) {
+			util.Respond(w, errs.NewValidationError(vErrors), http.StatusUnprocessableEntity)
+			return
+		}
+		if len(batch.MessageIds) > internal.MaxBatchSize {
+			sizeErr := errs.NewParamInvalidError("messageIds", fmt.Sprintf("Must contain between 1 and %d message ids", internal.MaxBatchSize))
+			util.Respond(w, sizeErr, util.HttpStatusCodeFromAppError(sizeErr))
+			return
+		}
+
+		queueName := chi.URLParam(r, "queueName")
+		queue, queueErr := queueRepository.GetQueue(queueName)
+		if queueErr != nil {
+			util.Respond(w, queueErr, util.HttpStatusCodeFromAppError(queueErr))
+			return
+		}
+
+		results := make([]messageBatchResult, len(batch.MessageIds))
+		for i, messageId := range batch.MessageIds {
+			nackErr := routing.Nack(queueRepository, exchangeRepository, queue, uuid.MustParse(messageId), requeue, delay)
+			results[i] = messageBatchResult{MessageId: messageId, Success: nackErr == nil, Error: nackErr}
+		}
+
+		util.Respond(w, results, http.StatusOK)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+	"github.com/melyouz/risala/broker/internal/storage"
+	"github.com/melyouz/risala/broker/internal/testing/util"
+	"github.com/melyouz/risala/broker/internal/validation"
+)
+
+func setupQueueMessageBatchNackTest(t *testing.T, queues map[string]*internal.Queue, queueName string, body map[string]interface{}, query string) (*httptest.ResponseRecorder, *http.Request) {
+	t.Helper()
+
+	queueRepository := storage.NewInMemoryQueueRepository(queues)
+	exchangeRepository := storage.NewInMemoryExchangeRepository(map[string]*internal.Exchange{})
+
+	requestBody, _ := json.Marshal(body)
+	path := fmt.Sprintf("%s/queues/%s/messages/batch/nack?%s", util.ApiV1BasePath, queueName, query)
+	request := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(requestBody))
+	response := httptest.NewRecorder()
+
+	routerCtx := chi.NewRouteContext()
+	routerCtx.URLParams.Add("queueName", queueName)
+	request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routerCtx))
+
+	HandleQueueMessageBatchNack(queueRepository, exchangeRepository, validation.NewJSONValidator())(response, request)
+
+	return response, request
+}
+
+func TestHandleQueueMessageBatchNack(t *testing.T) {
+
+	queues := map[string]*internal.Queue{
+		"events":                     util.NewTestQueueDurableWithoutMessages("events"),
+		internal.DeadLetterQueueName: util.NewTestSystemQueueWithoutMessages(internal.DeadLetterQueueName),
+	}
+
+	t.Run("Dead-letters messages & reports result per message", func(t *testing.T) {
+		messages := []*internal.Message{
+			{Id: uuid.New(), Payload: "Message 1", Processing: true},
+			{Id: uuid.New(), Payload: "Message 2", Processing: true},
+		}
+		queues["events"].RestoreMessages(messages)
+		queues[internal.DeadLetterQueueName].RestoreMessages([]*internal.Message{})
+		unknownMessageId := uuid.New().String()
+		messageIds := []string{messages[0].Id.String(), messages[1].Id.String(), unknownMessageId}
+
+		response, _ := setupQueueMessageBatchNackTest(t, queues, "events", map[string]interface{}{
+			"messageIds": messageIds,
+		}, "")
+
+		util.AssertOk(t, response)
+		jsonResponse := util.JSONCollectionResponse(response)
+		assert.Len(t, jsonResponse, 3)
+		assert.Equal(t, true, jsonResponse[0]["success"])
+		assert.Equal(t, true, jsonResponse[1]["success"])
+		assert.Equal(t, false, jsonResponse[2]["success"])
+		assert.Equal(t, unknownMessageId, jsonResponse[2]["messageId"])
+		assert.Len(t, queues["events"].GetMessages(), 0)
+		assert.Len(t, queues[internal.DeadLetterQueueName].GetMessages(), 2)
+	})
+
+	t.Run("Requeues messages when requested", func(t *testing.T) {
+		messages := []*internal.Message{
+			{Id: uuid.New(), Payload: "Message 1", Processing: true},
+			{Id: uuid.New(), Payload: "Message 2", Processing: true},
+		}
+		queues["events"].RestoreMessages(messages)
+		queues[internal.DeadLetterQueueName].RestoreMessages([]*internal.Message{})
+
+		response, _ := setupQueueMessageBatchNackTest(t, queues, "events", map[string]interface{}{
+			"messageIds": []string{messages[0].Id.String(), messages[1].Id.String()},
+		}, "requeue=true")
+
+		util.AssertOk(t, response)
+		assert.Len(t, queues["events"].GetMessages(), 2)
+		assert.False(t, messages[0].IsProcessing())
+		assert.False(t, messages[1].IsProcessing())
+		assert.Len(t, queues[internal.DeadLetterQueueName].GetMessages(), 0)
+	})
+
+	t.Run("Returns bad request when delay is invalid", func(t *testing.T) {
+		response, _ := setupQueueMessageBatchNackTest(t, queues, "events", map[string]interface{}{
+			"messageIds": []string{uuid.New().String()},
+		}, "requeue=true&delay=whatever")
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Equal(t, "delay", util.JSONItemResponse(response)["param"])
+	})
+
+	t.Run("Returns bad request when too many message ids supplied", func(t *testing.T) {
+		messageIds := make([]string, internal.MaxBatchSize+1)
+		for i := range messageIds {
+			messageIds[i] = uuid.New().String()
+		}
+
+		response, _ := setupQueueMessageBatchNackTest(t, queues, "events", map[string]interface{}{
+			"messageIds": messageIds,
+		}, "")
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, errs.ParamInvalidErrorCode, jsonResponse["code"])
+		assert.Equal(t, "messageIds", jsonResponse["param"])
+	})
+}
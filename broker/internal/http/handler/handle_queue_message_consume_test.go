@@ -66,7 +66,7 @@ func TestHandleQueueMessageConsume(t *testing.T) {
 			{Id: uuid.New(), Payload: "Message 4"},
 			{Id: uuid.New(), Payload: "Message 5"},
 		}
-		queues["events"].Messages = messages
+		queues["events"].RestoreMessages(messages)
 		initialMessageCount := len(messages)
 
 		response, _ := setupQueueMessageConsumeTest(t, queues, "events", "1")
@@ -77,7 +77,7 @@ func TestHandleQueueMessageConsume(t *testing.T) {
 		assert.Len(t, jsonResponse, 1)
 		assert.NotEmpty(t, jsonResponse[0]["id"])
 		assert.Equal(t, "Message 1", jsonResponse[0]["payload"])
-		assert.Len(t, queues["events"].Messages, initialMessageCount-1)
+		assert.Len(t, queues["events"].GetMessages(), initialMessageCount-1)
 	})
 
 	t.Run("Returns N messages when limit=N and available messages > N", func(t *testing.T) {
@@ -88,7 +88,7 @@ func TestHandleQueueMessageConsume(t *testing.T) {
 			{Id: uuid.New(), Payload: "Message 4"},
 			{Id: uuid.New(), Payload: "Message 5"},
 		}
-		queues["events"].Messages = messages
+		queues["events"].RestoreMessages(messages)
 		initialMessageCount := len(messages)
 
 		response, _ := setupQueueMessageConsumeTest(t, queues, "events", "2")
@@ -99,7 +99,7 @@ func TestHandleQueueMessageConsume(t *testing.T) {
 		assert.Len(t, jsonResponse, 2)
 		assert.Equal(t, "Message 1", jsonResponse[0]["payload"])
 		assert.Equal(t, "Message 2", jsonResponse[1]["payload"])
-		assert.Len(t, queues["events"].Messages, initialMessageCount-2)
+		assert.Len(t, queues["events"].GetMessages(), initialMessageCount-2)
 	})
 
 	t.Run("Returns all messages when limit=N and available messages < N", func(t *testing.T) {
@@ -110,7 +110,7 @@ func TestHandleQueueMessageConsume(t *testing.T) {
 			{Id: uuid.New(), Payload: "Message 4"},
 			{Id: uuid.New(), Payload: "Message 5"},
 		}
-		queues["events"].Messages = messages
+		queues["events"].RestoreMessages(messages)
 		initialMessageCount := len(messages)
 
 		response, _ := setupQueueMessageConsumeTest(t, queues, "events", "200")
@@ -123,7 +123,7 @@ func TestHandleQueueMessageConsume(t *testing.T) {
 			assert.NotEmpty(t, message["id"])
 			assert.Equal(t, fmt.Sprintf("Message %d", i+1), message["payload"])
 		}
-		assert.Empty(t, queues["events"].Messages)
+		assert.Empty(t, queues["events"].GetMessages())
 	})
 
 	t.Run("Returns not found when queue does not exist", func(t *testing.T) {
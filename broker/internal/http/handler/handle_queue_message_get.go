@@ -5,16 +5,39 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
 	"github.com/melyouz/risala/broker/internal/http/util"
 	"github.com/melyouz/risala/broker/internal/storage"
 )
 
 func HandleQueueMessageGet(queueRepository storage.QueueRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		visibilityTimeoutParamName := "visibilityTimeout"
+		visibilityTimeout, paramErr := util.DurationQueryParam(r, visibilityTimeoutParamName)
+		if paramErr == nil && visibilityTimeout > internal.MaxVisibilityTimeout {
+			paramErr = errs.NewParamInvalidError(visibilityTimeoutParamName, fmt.Sprintf("Must not exceed %s", internal.MaxVisibilityTimeout))
+		}
+		if paramErr != nil {
+			util.Respond(w, paramErr, util.HttpStatusCodeFromAppError(paramErr))
+			return
+		}
+
+		waitParamName := "wait"
+		wait, paramErr := util.DurationQueryParam(r, waitParamName)
+		if paramErr == nil && wait > internal.MaxDequeueWait {
+			paramErr = errs.NewParamInvalidError(waitParamName, fmt.Sprintf("Must not exceed %s", internal.MaxDequeueWait))
+		}
+		if paramErr != nil {
+			util.Respond(w, paramErr, util.HttpStatusCodeFromAppError(paramErr))
+			return
+		}
+
 		queueName := chi.URLParam(r, "queueName")
 		queue, queueErr := queueRepository.GetQueue(queueName)
 		if queueErr != nil {
@@ -22,12 +45,12 @@ func HandleQueueMessageGet(queueRepository storage.QueueRepository) http.Handler
 			return
 		}
 
-		message := queue.Dequeue()
+		message := queue.DequeueWithWait(r.Context(), visibilityTimeout, wait)
 		if message == nil {
 			util.Respond(w, nil, http.StatusNoContent)
 			return
 		}
 
-		util.Respond(w, message, http.StatusOK)
+		util.RespondMessage(w, r, message, http.StatusOK)
 	}
 }
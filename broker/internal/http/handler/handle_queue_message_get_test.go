@@ -11,23 +11,44 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
 	"github.com/melyouz/risala/broker/internal/storage"
 	"github.com/melyouz/risala/broker/internal/testing/util"
 )
 
-func setupQueueMessageGetTest(t *testing.T, queues map[string]*internal.Queue, queueName string) (*httptest.ResponseRecorder, *http.Request) {
+func setupQueueMessageGetTest(t *testing.T, queues map[string]*internal.Queue, queueName string, query string) (*httptest.ResponseRecorder, *http.Request) {
+	t.Helper()
+
+	queueRepository := storage.NewInMemoryQueueRepository(queues)
+
+	path := fmt.Sprintf("%s/queues/%s/messages/get?%s", util.ApiV1BasePath, queueName, query)
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	response := httptest.NewRecorder()
+
+	routerCtx := chi.NewRouteContext()
+	routerCtx.URLParams.Add("queueName", queueName)
+	request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routerCtx))
+
+	HandleQueueMessageGet(queueRepository)(response, request)
+
+	return response, request
+}
+
+func setupQueueMessageGetRawTest(t *testing.T, queues map[string]*internal.Queue, queueName string, accept string) (*httptest.ResponseRecorder, *http.Request) {
 	t.Helper()
 
 	queueRepository := storage.NewInMemoryQueueRepository(queues)
 
 	path := fmt.Sprintf("%s/queues/%s/messages/get", util.ApiV1BasePath, queueName)
 	request := httptest.NewRequest(http.MethodGet, path, nil)
+	request.Header.Set("Accept", accept)
 	response := httptest.NewRecorder()
 
 	routerCtx := chi.NewRouteContext()
@@ -54,11 +75,11 @@ func TestHandleQueueMessageGet(t *testing.T) {
 			{Id: uuid.New(), Payload: "Message 4"},
 			{Id: uuid.New(), Payload: "Message 5"},
 		}
-		queues["events"].Messages = messages
+		queues["events"].RestoreMessages(messages)
 		initialMessageCount := len(messages)
 		firstMessage := messages[0]
 
-		response, _ := setupQueueMessageGetTest(t, queues, "events")
+		response, _ := setupQueueMessageGetTest(t, queues, "events", "")
 
 		assert.True(t, firstMessage.IsProcessing())
 		util.AssertOk(t, response)
@@ -66,18 +87,134 @@ func TestHandleQueueMessageGet(t *testing.T) {
 		_ = json.Unmarshal(response.Body.Bytes(), &jsonResponse)
 		assert.Equal(t, firstMessage.Id.String(), jsonResponse["id"])
 		assert.Equal(t, firstMessage.Payload, jsonResponse["payload"])
-		assert.Len(t, queues["events"].Messages, initialMessageCount)
+		assert.Equal(t, float64(1), jsonResponse["deliveryCount"])
+		assert.Len(t, queues["events"].GetMessages(), initialMessageCount)
+	})
+
+	t.Run("Hides message being processed until its visibility timeout expires", func(t *testing.T) {
+		messages := []*internal.Message{
+			{Id: uuid.New(), Payload: "Message 1"},
+		}
+		queues["events"].RestoreMessages(messages)
+
+		response, _ := setupQueueMessageGetTest(t, queues, "events", "visibilityTimeout=1h")
+		util.AssertOk(t, response)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), messages[0].ProcessingUntil, time.Minute)
+
+		response, _ = setupQueueMessageGetTest(t, queues, "events", "")
+		util.AssertNoContent(t, response)
+	})
+
+	t.Run("Redelivers message when its visibility timeout expired", func(t *testing.T) {
+		messages := []*internal.Message{
+			{Id: uuid.New(), Payload: "Message 1", Processing: true, ProcessingUntil: time.Now().Add(-time.Second), DeliveryCount: 1},
+			{Id: uuid.New(), Payload: "Message 2"},
+		}
+		queues["events"].RestoreMessages(messages)
+
+		response, _ := setupQueueMessageGetTest(t, queues, "events", "")
+
+		util.AssertOk(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, messages[0].Id.String(), jsonResponse["id"])
+		assert.Equal(t, float64(2), jsonResponse["deliveryCount"])
+		assert.True(t, messages[0].IsProcessing())
+	})
+
+	t.Run("Returns bad request when visibility timeout is invalid", func(t *testing.T) {
+		for _, visibilityTimeout := range []string{"whatever", "-1", "13h"} {
+			response, _ := setupQueueMessageGetTest(t, queues, "events", "visibilityTimeout="+visibilityTimeout)
+
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+			jsonResponse := util.JSONItemResponse(response)
+			assert.Equal(t, errs.ParamInvalidErrorCode, jsonResponse["code"])
+			assert.Equal(t, "visibilityTimeout", jsonResponse["param"])
+		}
+	})
+
+	t.Run("Returns raw payload when JSON is not accepted", func(t *testing.T) {
+		message := &internal.Message{
+			Id:            uuid.New(),
+			Payload:       string([]byte{0x0a, 0x02, 0xff}),
+			RoutingKey:    "product.created",
+			Headers:       map[string]string{"type": "product.created"},
+			ContentType:   "application/x-protobuf",
+			CorrelationId: "9d1c4e2a",
+		}
+		queues["events"].RestoreMessages([]*internal.Message{message})
+
+		response, _ := setupQueueMessageGetRawTest(t, queues, "events", "application/x-protobuf")
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, []byte{0x0a, 0x02, 0xff}, response.Body.Bytes())
+		assert.Equal(t, "application/x-protobuf", response.Header().Get("Content-Type"))
+		assert.Equal(t, message.Id.String(), response.Header().Get("X-Message-Id"))
+		assert.Equal(t, "product.created", response.Header().Get("X-Routing-Key"))
+		assert.Equal(t, "9d1c4e2a", response.Header().Get("X-Correlation-Id"))
+		assert.Equal(t, "product.created", response.Header().Get("X-Header-Type"))
+		assert.Equal(t, "1", response.Header().Get("X-Delivery-Count"))
+		assert.True(t, message.IsProcessing())
+	})
+
+	t.Run("Returns base64 encoded binary payload when JSON is accepted", func(t *testing.T) {
+		queues["events"].RestoreMessages([]*internal.Message{
+			{Id: uuid.New(), Payload: string([]byte{0x0a, 0x02, 0xff})},
+		})
+
+		response, _ := setupQueueMessageGetRawTest(t, queues, "events", "application/json")
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, "CgL/", jsonResponse["payload"])
+		assert.Equal(t, "base64", jsonResponse["payloadEncoding"])
+	})
+
+	t.Run("Waits for message to be enqueued when wait supplied", func(t *testing.T) {
+		queues["events"].RestoreMessages([]*internal.Message{})
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_ = queues["events"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 1"})
+		}()
+
+		startedAt := time.Now()
+		response, _ := setupQueueMessageGetTest(t, queues, "events", "wait=5s")
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Less(t, time.Since(startedAt), 5*time.Second)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, "Message 1", jsonResponse["payload"])
+	})
+
+	t.Run("Returns no content when no message is enqueued before wait elapses", func(t *testing.T) {
+		queues["events"].RestoreMessages([]*internal.Message{})
+
+		startedAt := time.Now()
+		response, _ := setupQueueMessageGetTest(t, queues, "events", "wait=100ms")
+
+		assert.Equal(t, http.StatusNoContent, response.Code)
+		assert.GreaterOrEqual(t, time.Since(startedAt), 100*time.Millisecond)
+	})
+
+	t.Run("Returns bad request when wait is invalid", func(t *testing.T) {
+		for _, wait := range []string{"whatever", "-1", "21s"} {
+			response, _ := setupQueueMessageGetTest(t, queues, "events", "wait="+wait)
+
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+			jsonResponse := util.JSONItemResponse(response)
+			assert.Equal(t, errs.ParamInvalidErrorCode, jsonResponse["code"])
+			assert.Equal(t, "wait", jsonResponse["param"])
+		}
 	})
 
 	t.Run("Returns no content when no messages", func(t *testing.T) {
-		response, _ := setupQueueMessageGetTest(t, queues, "tmp")
+		response, _ := setupQueueMessageGetTest(t, queues, "tmp", "")
 
 		util.AssertNoContent(t, response)
 	})
 
 	t.Run("Returns not found when queue does not exist", func(t *testing.T) {
 
-		response, _ := setupQueueMessageGetTest(t, queues, "nonExistingQueueName")
+		response, _ := setupQueueMessageGetTest(t, queues, "nonExistingQueueName", "")
 
 		util.AssertNotFound(t, response, "QUEUE_NOT_FOUND", "Queue 'nonExistingQueueName' not found")
 	})
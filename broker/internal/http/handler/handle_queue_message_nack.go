@@ -5,7 +5,9 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -13,10 +15,11 @@ import (
 	"github.com/melyouz/risala/broker/internal"
 	"github.com/melyouz/risala/broker/internal/errs"
 	"github.com/melyouz/risala/broker/internal/http/util"
+	"github.com/melyouz/risala/broker/internal/routing"
 	"github.com/melyouz/risala/broker/internal/storage"
 )
 
-func HandleQueueMessageNack(queueRepository storage.QueueRepository) http.HandlerFunc {
+func HandleQueueMessageNack(queueRepository storage.QueueRepository, exchangeRepository storage.ExchangeRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		queueName := chi.URLParam(r, "queueName")
 		messageIdParamName := "messageId"
@@ -27,30 +30,42 @@ func HandleQueueMessageNack(queueRepository storage.QueueRepository) http.Handle
 			return
 		}
 
+		requeue, delay, paramErr := nackQueryParams(r)
+		if paramErr != nil {
+			util.Respond(w, paramErr, util.HttpStatusCodeFromAppError(paramErr))
+			return
+		}
+
 		queue, queueErr := queueRepository.GetQueue(queueName)
 		if queueErr != nil {
 			util.Respond(w, queueErr, util.HttpStatusCodeFromAppError(queueErr))
 			return
 		}
 
-		message, nackErr := queue.Nack(messageId)
+		nackErr := routing.Nack(queueRepository, exchangeRepository, queue, messageId, requeue, delay)
 		if nackErr != nil {
 			util.Respond(w, nackErr, util.HttpStatusCodeFromAppError(nackErr))
 			return
 		}
 
-		deadLetterQueue, deadLetterQueueErr := queueRepository.GetQueue(internal.DeadLetterQueueName)
-		if deadLetterQueueErr != nil {
-			util.Respond(w, deadLetterQueueErr, util.HttpStatusCodeFromAppError(deadLetterQueueErr))
-			return
-		}
+		util.Respond(w, nil, http.StatusNoContent)
+	}
+}
 
-		enqueueErr := deadLetterQueue.Enqueue(message)
-		if enqueueErr != nil {
-			util.Respond(w, enqueueErr, util.HttpStatusCodeFromAppError(enqueueErr))
-			return
-		}
+func nackQueryParams(r *http.Request) (requeue bool, delay time.Duration, err errs.AppError) {
+	requeue, err = util.BoolQueryParam(r, "requeue")
+	if err != nil {
+		return false, 0, err
+	}
 
-		util.Respond(w, nil, http.StatusNoContent)
+	delayParamName := "delay"
+	delay, err = util.DurationQueryParam(r, delayParamName)
+	if err == nil && delay > internal.MaxVisibilityTimeout {
+		err = errs.NewParamInvalidError(delayParamName, fmt.Sprintf("Must not exceed %s", internal.MaxVisibilityTimeout))
+	}
+	if err != nil {
+		return false, 0, err
 	}
+
+	return requeue, delay, nil
 }
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -21,12 +22,19 @@ import (
 	"github.com/melyouz/risala/broker/internal/testing/util"
 )
 
-func setupQueueMessageNackTest(t *testing.T, queues map[string]*internal.Queue, queueName string, messageId uuid.UUID) (*httptest.ResponseRecorder, *http.Request) {
+func setupQueueMessageNackTest(t *testing.T, queues map[string]*internal.Queue, queueName string, messageId uuid.UUID, query string) (*httptest.ResponseRecorder, *http.Request) {
+	t.Helper()
+
+	return setupQueueMessageNackWithExchangesTest(t, queues, map[string]*internal.Exchange{}, queueName, messageId, query)
+}
+
+func setupQueueMessageNackWithExchangesTest(t *testing.T, queues map[string]*internal.Queue, exchanges map[string]*internal.Exchange, queueName string, messageId uuid.UUID, query string) (*httptest.ResponseRecorder, *http.Request) {
 	t.Helper()
 
 	queueRepository := storage.NewInMemoryQueueRepository(queues)
+	exchangeRepository := storage.NewInMemoryExchangeRepository(exchanges)
 
-	path := fmt.Sprintf("%s/queues/%s/messages/%s/nack", util.ApiV1BasePath, queueName, messageId.String())
+	path := fmt.Sprintf("%s/queues/%s/messages/%s/nack?%s", util.ApiV1BasePath, queueName, messageId.String(), query)
 	request := httptest.NewRequest(http.MethodGet, path, nil)
 	response := httptest.NewRecorder()
 
@@ -35,7 +43,7 @@ func setupQueueMessageNackTest(t *testing.T, queues map[string]*internal.Queue,
 	routerCtx.URLParams.Add("messageId", messageId.String())
 	request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routerCtx))
 
-	HandleQueueMessageNack(queueRepository)(response, request)
+	HandleQueueMessageNack(queueRepository, exchangeRepository)(response, request)
 
 	return response, request
 }
@@ -56,16 +64,205 @@ func TestHandleQueueMessageNack(t *testing.T) {
 			{Id: uuid.New(), Payload: "Message 4"},
 			{Id: uuid.New(), Payload: "Message 5"},
 		}
-		queues["events"].Messages = messages
+		queues["events"].RestoreMessages(messages)
 		initialMessageCount := len(messages)
 		messageId := messages[0].Id
 
-		response, _ := setupQueueMessageNackTest(t, queues, "events", messageId)
+		response, _ := setupQueueMessageNackTest(t, queues, "events", messageId, "")
+
+		util.AssertNoContent(t, response)
+		assert.Len(t, queues["events"].GetMessages(), initialMessageCount-1)
+		assert.Len(t, queues[internal.DeadLetterQueueName].GetMessages(), 1)
+		assert.Equal(t, queues[internal.DeadLetterQueueName].GetMessages()[0].Id, messageId)
+	})
+
+	t.Run("Requeues message when requeue requested", func(t *testing.T) {
+		messages := []*internal.Message{
+			{Id: uuid.New(), Payload: "Message 1", Processing: true, DeliveryCount: 1},
+			{Id: uuid.New(), Payload: "Message 2"},
+		}
+		queues["events"].RestoreMessages(messages)
+		queues[internal.DeadLetterQueueName].RestoreMessages([]*internal.Message{})
+		messageId := messages[0].Id
+
+		response, _ := setupQueueMessageNackTest(t, queues, "events", messageId, "requeue=true")
+
+		util.AssertNoContent(t, response)
+		assert.Len(t, queues["events"].GetMessages(), 2)
+		assert.False(t, messages[0].IsProcessing())
+		assert.Empty(t, queues[internal.DeadLetterQueueName].GetMessages())
+		assert.Equal(t, messageId, queues["events"].Dequeue().Id)
+	})
+
+	t.Run("Requeues message hidden until delay elapses when requeue with delay requested", func(t *testing.T) {
+		messages := []*internal.Message{
+			{Id: uuid.New(), Payload: "Message 1", Processing: true, DeliveryCount: 1},
+			{Id: uuid.New(), Payload: "Message 2"},
+		}
+		queues["events"].RestoreMessages(messages)
+		messageId := messages[0].Id
+
+		response, _ := setupQueueMessageNackTest(t, queues, "events", messageId, "requeue=true&delay=1h")
+
+		util.AssertNoContent(t, response)
+		assert.Len(t, queues["events"].GetMessages(), 2)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), messages[0].AvailableAt, time.Minute)
+		assert.Equal(t, messages[1].Id, queues["events"].Dequeue().Id)
+		assert.Nil(t, queues["events"].Dequeue())
+	})
+
+	t.Run("Dead-letters message when requeue requested but max deliveries reached", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events":                     {Name: "events", Durability: internal.Durability.DURABLE, MaxDeliveries: 3},
+			internal.DeadLetterQueueName: util.NewTestSystemQueueWithoutMessages(internal.DeadLetterQueueName),
+		}
+		messages := []*internal.Message{
+			{Id: uuid.New(), Payload: "Message 1", Processing: true, DeliveryCount: 2},
+			{Id: uuid.New(), Payload: "Message 2", Processing: true, DeliveryCount: 3},
+		}
+		queues["events"].RestoreMessages(messages)
+		belowLimitMessageId := messages[0].Id
+		limitReachedMessageId := messages[1].Id
+
+		response, _ := setupQueueMessageNackTest(t, queues, "events", belowLimitMessageId, "requeue=true")
+
+		util.AssertNoContent(t, response)
+		assert.Len(t, queues["events"].GetMessages(), 2)
+		assert.Empty(t, queues[internal.DeadLetterQueueName].GetMessages())
+
+		response, _ = setupQueueMessageNackTest(t, queues, "events", limitReachedMessageId, "requeue=true")
+
+		util.AssertNoContent(t, response)
+		assert.Len(t, queues["events"].GetMessages(), 1)
+		assert.Len(t, queues[internal.DeadLetterQueueName].GetMessages(), 1)
+		assert.Equal(t, limitReachedMessageId, queues[internal.DeadLetterQueueName].GetMessages()[0].Id)
+	})
+
+	t.Run("Records dead-letter headers on dead-lettered message", func(t *testing.T) {
+		messages := []*internal.Message{
+			{
+				Id:            uuid.New(),
+				Payload:       "Message 1",
+				RoutingKey:    "product.created",
+				Exchange:      "app.internal",
+				Headers:       map[string]string{"type": "product"},
+				ContentType:   "text/plain",
+				CorrelationId: "9d1c4e2a",
+				ReplyTo:       "replies",
+				Timestamp:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				Processing:    true,
+			},
+		}
+		queues["events"].RestoreMessages(messages)
+		queues[internal.DeadLetterQueueName].RestoreMessages([]*internal.Message{})
+		messageId := messages[0].Id
+
+		response, _ := setupQueueMessageNackTest(t, queues, "events", messageId, "")
 
 		util.AssertNoContent(t, response)
-		assert.Len(t, queues["events"].Messages, initialMessageCount-1)
-		assert.Len(t, queues[internal.DeadLetterQueueName].Messages, 1)
-		assert.Equal(t, queues[internal.DeadLetterQueueName].Messages[0].Id, messageId)
+		assert.Len(t, queues[internal.DeadLetterQueueName].GetMessages(), 1)
+		deadLetter := queues[internal.DeadLetterQueueName].GetMessages()[0]
+		assert.Equal(t, messageId, deadLetter.Id)
+		assert.Equal(t, "Message 1", deadLetter.Payload)
+		assert.False(t, deadLetter.IsProcessing())
+		assert.Equal(t, "product", deadLetter.Headers["type"])
+		assert.Equal(t, "text/plain", deadLetter.ContentType)
+		assert.Equal(t, "9d1c4e2a", deadLetter.CorrelationId)
+		assert.Equal(t, "replies", deadLetter.ReplyTo)
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), deadLetter.Timestamp)
+		assert.Equal(t, "nack", deadLetter.Headers[internal.DeathReasonHeader])
+		assert.Equal(t, "events", deadLetter.Headers[internal.DeathQueueHeader])
+		assert.Equal(t, "app.internal", deadLetter.Headers[internal.DeathExchangeHeader])
+		assert.Equal(t, "product.created", deadLetter.Headers[internal.DeathRoutingKeyHeader])
+		deathTime, timeErr := time.Parse(time.RFC3339, deadLetter.Headers[internal.DeathTimeHeader])
+		assert.Nil(t, timeErr)
+		assert.WithinDuration(t, time.Now(), deathTime, time.Minute)
+	})
+
+	t.Run("Routes dead-lettered message through the queue dead-letter exchange", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders": {
+				Name:                 "orders",
+				Durability:           internal.Durability.DURABLE,
+				MaxDeliveries:        1,
+				DeadLetterExchange:   "app.dead-letter",
+				DeadLetterRoutingKey: "dead.orders",
+			},
+			"orders.dead-letter":         util.NewTestQueueDurableWithoutMessages("orders.dead-letter"),
+			internal.DeadLetterQueueName: util.NewTestSystemQueueWithoutMessages(internal.DeadLetterQueueName),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.dead-letter": util.NewTestExchange("app.dead-letter", internal.ExchangeTypes.DIRECT, []*internal.Binding{
+				{Id: uuid.New(), Queue: "orders.dead-letter", RoutingKey: "dead.orders"},
+			}),
+		}
+		messages := []*internal.Message{
+			{Id: uuid.New(), Payload: "Message 1", RoutingKey: "order.created", Processing: true, DeliveryCount: 1},
+		}
+		queues["orders"].RestoreMessages(messages)
+		messageId := messages[0].Id
+
+		response, _ := setupQueueMessageNackWithExchangesTest(t, queues, exchanges, "orders", messageId, "requeue=true")
+
+		util.AssertNoContent(t, response)
+		assert.Empty(t, queues["orders"].GetMessages())
+		assert.Empty(t, queues[internal.DeadLetterQueueName].GetMessages())
+		assert.Len(t, queues["orders.dead-letter"].GetMessages(), 1)
+		deadLetter := queues["orders.dead-letter"].GetMessages()[0]
+		assert.Equal(t, messageId, deadLetter.Id)
+		assert.Equal(t, "dead.orders", deadLetter.RoutingKey)
+		assert.Equal(t, "app.dead-letter", deadLetter.Exchange)
+		assert.Equal(t, "max-deliveries", deadLetter.Headers[internal.DeathReasonHeader])
+		assert.Equal(t, "orders", deadLetter.Headers[internal.DeathQueueHeader])
+		assert.Equal(t, "order.created", deadLetter.Headers[internal.DeathRoutingKeyHeader])
+	})
+
+	t.Run("Falls back to system dead-letter queue when dead-letter exchange does not route the message", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders": {
+				Name:               "orders",
+				Durability:         internal.Durability.DURABLE,
+				DeadLetterExchange: "app.dead-letter",
+			},
+			"orders.dead-letter":         util.NewTestQueueDurableWithoutMessages("orders.dead-letter"),
+			internal.DeadLetterQueueName: util.NewTestSystemQueueWithoutMessages(internal.DeadLetterQueueName),
+		}
+		exchanges := map[string]*internal.Exchange{
+			"app.dead-letter": util.NewTestExchange("app.dead-letter", internal.ExchangeTypes.DIRECT, []*internal.Binding{
+				{Id: uuid.New(), Queue: "orders.dead-letter", RoutingKey: "dead.orders"},
+			}),
+		}
+		messages := []*internal.Message{
+			{Id: uuid.New(), Payload: "Message 1", RoutingKey: "order.created", Processing: true},
+		}
+		queues["orders"].RestoreMessages(messages)
+		messageId := messages[0].Id
+
+		response, _ := setupQueueMessageNackWithExchangesTest(t, queues, exchanges, "orders", messageId, "")
+
+		util.AssertNoContent(t, response)
+		assert.Empty(t, queues["orders.dead-letter"].GetMessages())
+		assert.Len(t, queues[internal.DeadLetterQueueName].GetMessages(), 1)
+		assert.Equal(t, messageId, queues[internal.DeadLetterQueueName].GetMessages()[0].Id)
+		assert.Equal(t, "orders", queues[internal.DeadLetterQueueName].GetMessages()[0].Headers[internal.DeathQueueHeader])
+	})
+
+	t.Run("Returns bad request when requeue or delay are invalid", func(t *testing.T) {
+		messageId := uuid.New()
+		testCases := map[string]string{
+			"requeue=whatever":       "requeue",
+			"requeue=true&delay=abc": "delay",
+			"requeue=true&delay=13h": "delay",
+		}
+
+		for query, expectedParam := range testCases {
+			response, _ := setupQueueMessageNackTest(t, queues, "events", messageId, query)
+
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+			jsonResponse := util.JSONItemResponse(response)
+			assert.Equal(t, errs.ParamInvalidErrorCode, jsonResponse["code"])
+			assert.Equal(t, expectedParam, jsonResponse["param"])
+		}
 	})
 
 	t.Run("Returns not found when message is not being processed", func(t *testing.T) {
@@ -73,10 +270,10 @@ func TestHandleQueueMessageNack(t *testing.T) {
 			{Id: uuid.New(), Payload: "Message 1"},
 			{Id: uuid.New(), Payload: "Message 2", Processing: true},
 		}
-		queues["events"].Messages = messages
+		queues["events"].RestoreMessages(messages)
 		messageId := messages[0].Id
 
-		response, _ := setupQueueMessageNackTest(t, queues, "events", messageId)
+		response, _ := setupQueueMessageNackTest(t, queues, "events", messageId, "")
 
 		util.AssertNotFound(t, response, errs.MessageNotFoundErrorCode, fmt.Sprintf("Message '%s' not found", messageId.String()))
 	})
@@ -84,7 +281,7 @@ func TestHandleQueueMessageNack(t *testing.T) {
 	t.Run("Returns not found when message does not exist", func(t *testing.T) {
 		messageId := uuid.New()
 
-		response, _ := setupQueueMessageNackTest(t, queues, "events", messageId)
+		response, _ := setupQueueMessageNackTest(t, queues, "events", messageId, "")
 
 		util.AssertNotFound(t, response, errs.MessageNotFoundErrorCode, fmt.Sprintf("Message '%s' not found", messageId.String()))
 	})
@@ -92,7 +289,7 @@ func TestHandleQueueMessageNack(t *testing.T) {
 	t.Run("Returns not found when queue does not exist", func(t *testing.T) {
 		messageId := uuid.New()
 
-		response, _ := setupQueueMessageNackTest(t, queues, "nonExistingQueueName", messageId)
+		response, _ := setupQueueMessageNackTest(t, queues, "nonExistingQueueName", messageId, "")
 
 		util.AssertNotFound(t, response, "QUEUE_NOT_FOUND", "Queue 'nonExistingQueueName' not found")
 	})
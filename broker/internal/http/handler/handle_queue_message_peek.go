@@ -10,6 +10,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/melyouz/risala/broker/internal/errs"
 	"github.com/melyouz/risala/broker/internal/http/util"
 	"github.com/melyouz/risala/broker/internal/storage"
 )
@@ -21,6 +22,13 @@ func HandleQueueMessagePeek(queueRepository storage.QueueRepository) http.Handle
 			limit = 1
 		}
 
+		// a raw body holds a single message
+		if limit > 1 && !util.AcceptsJSON(r) {
+			acceptErr := errs.NewNotAcceptableError("Peeking more than one message requires accepting application/json")
+			util.Respond(w, acceptErr, util.HttpStatusCodeFromAppError(acceptErr))
+			return
+		}
+
 		queueName := chi.URLParam(r, "queueName")
 		queue, queueErr := queueRepository.GetQueue(queueName)
 		if queueErr != nil {
@@ -34,6 +42,16 @@ func HandleQueueMessagePeek(queueRepository storage.QueueRepository) http.Handle
 			return
 		}
 
+		if !util.AcceptsJSON(r) {
+			if len(messages) == 0 {
+				util.Respond(w, nil, http.StatusNoContent)
+				return
+			}
+
+			util.RespondMessage(w, r, messages[0], http.StatusOK)
+			return
+		}
+
 		util.Respond(w, messages, http.StatusOK)
 	}
 }
@@ -66,7 +66,7 @@ func TestHandleQueueMessagePeek(t *testing.T) {
 	})
 
 	t.Run("Returns one message when no limit supplied", func(t *testing.T) {
-		initialMessageCount := len(queues["tmp"].Messages)
+		initialMessageCount := len(queues["tmp"].GetMessages())
 		response, _ := setupQueueMessagePeekTest(t, queues, "tmp", "1")
 
 		util.AssertOk(t, response)
@@ -75,11 +75,11 @@ func TestHandleQueueMessagePeek(t *testing.T) {
 		assert.Len(t, jsonResponse, 1)
 		assert.NotEmpty(t, jsonResponse[0]["id"])
 		assert.Equal(t, "Message 1", jsonResponse[0]["payload"])
-		assert.Len(t, queues["tmp"].Messages, initialMessageCount)
+		assert.Len(t, queues["tmp"].GetMessages(), initialMessageCount)
 	})
 
 	t.Run("Returns N messages when limit=N and available messages > N", func(t *testing.T) {
-		initialMessageCount := len(queues["tmp"].Messages)
+		initialMessageCount := len(queues["tmp"].GetMessages())
 		response, _ := setupQueueMessagePeekTest(t, queues, "tmp", "2")
 
 		util.AssertOk(t, response)
@@ -88,22 +88,61 @@ func TestHandleQueueMessagePeek(t *testing.T) {
 		assert.Len(t, jsonResponse, 2)
 		assert.Equal(t, "Message 1", jsonResponse[0]["payload"])
 		assert.Equal(t, "Message 2", jsonResponse[1]["payload"])
-		assert.Len(t, queues["tmp"].Messages, initialMessageCount)
+		assert.Len(t, queues["tmp"].GetMessages(), initialMessageCount)
 	})
 
 	t.Run("Returns all messages when limit=N and available messages < N", func(t *testing.T) {
-		initialMessageCount := len(queues["tmp"].Messages)
+		initialMessageCount := len(queues["tmp"].GetMessages())
 		response, _ := setupQueueMessagePeekTest(t, queues, "tmp", "200")
 
 		util.AssertOk(t, response)
 		var jsonResponse []map[string]interface{}
 		_ = json.Unmarshal(response.Body.Bytes(), &jsonResponse)
-		assert.Len(t, jsonResponse, len(queues["tmp"].Messages))
+		assert.Len(t, jsonResponse, len(queues["tmp"].GetMessages()))
 		for i, message := range jsonResponse {
 			assert.NotEmpty(t, message["id"])
 			assert.Equal(t, fmt.Sprintf("Message %d", i+1), message["payload"])
 		}
-		assert.Len(t, queues["tmp"].Messages, initialMessageCount)
+		assert.Len(t, queues["tmp"].GetMessages(), initialMessageCount)
+	})
+
+	t.Run("Returns first message raw payload when JSON is not accepted", func(t *testing.T) {
+		queueRepository := storage.NewInMemoryQueueRepository(queues)
+		queues["events"].RestoreMessages([]*internal.Message{
+			{Id: uuid.New(), Payload: "Message 1", ContentType: "text/plain"},
+			{Id: uuid.New(), Payload: "Message 2", ContentType: "text/plain"},
+		})
+
+		path := fmt.Sprintf("%s/queues/events/messages/peek", util.ApiV1BasePath)
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		request.Header.Set("Accept", "text/plain")
+		routerCtx := chi.NewRouteContext()
+		routerCtx.URLParams.Add("queueName", "events")
+		request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routerCtx))
+		response := httptest.NewRecorder()
+
+		HandleQueueMessagePeek(queueRepository)(response, request)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "Message 1", response.Body.String())
+		assert.Equal(t, "text/plain", response.Header().Get("Content-Type"))
+		assert.False(t, queues["events"].GetMessages()[0].IsProcessing())
+	})
+
+	t.Run("Returns not acceptable when peeking several messages without accepting JSON", func(t *testing.T) {
+		queueRepository := storage.NewInMemoryQueueRepository(queues)
+
+		path := fmt.Sprintf("%s/queues/tmp/messages/peek?limit=2", util.ApiV1BasePath)
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		request.Header.Set("Accept", "text/plain")
+		routerCtx := chi.NewRouteContext()
+		routerCtx.URLParams.Add("queueName", "tmp")
+		request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routerCtx))
+		response := httptest.NewRecorder()
+
+		HandleQueueMessagePeek(queueRepository)(response, request)
+
+		util.AssertNotAcceptable(t, response, "NOT_ACCEPTABLE", "Peeking more than one message requires accepting application/json")
 	})
 
 	t.Run("Returns not found when queue does not exist", func(t *testing.T) {
@@ -7,6 +7,7 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
@@ -22,7 +23,12 @@ func HandleQueueMessagePublish(queueRepository storage.QueueRepository, validate
 	return func(w http.ResponseWriter, r *http.Request) {
 		var message internal.Message
 		message.Id = uuid.New()
-		util.Decode(r, &message)
+		if decodeErr := util.DecodeMessage(r, &message); decodeErr != nil {
+			util.Respond(w, decodeErr, util.HttpStatusCodeFromAppError(decodeErr))
+			return
+		}
+		message.Exchange = ""
+		message.Timestamp = time.Now().UTC()
 
 		var vErrors validator.ValidationErrors
 		if errors.As(validate.Struct(&message), &vErrors) {
@@ -43,6 +49,15 @@ func HandleQueueMessagePublish(queueRepository storage.QueueRepository, validate
 			return
 		}
 
-		util.Respond(w, &message, http.StatusCreated)
+		util.Respond(w, &message, publishedStatusCode(&message))
+	}
+}
+
+// publishedStatusCode tells apart a duplicate, pointing to the message published originally, from a new message
+func publishedStatusCode(message *internal.Message) int {
+	if message.Duplicate {
+		return http.StatusOK
 	}
+
+	return http.StatusCreated
 }
@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+	"github.com/melyouz/risala/broker/internal/http/util"
+	"github.com/melyouz/risala/broker/internal/storage"
+)
+
+func HandleQueueMessagePublishBatch(queueRepository storage.QueueRepository, validate *validator.Validate) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var messages []*internal.Message
+		if decodeErr := util.DecodeMessages(r, &messages); decodeErr != nil {
+			util.Respond(w, decodeErr, util.HttpStatusCodeFromAppError(decodeErr))
+			return
+		}
+
+		queueName := chi.URLParam(r, "queueName")
+		queue, queueErr := queueRepository.GetQueue(queueName)
+		if queueErr != nil {
+			util.Respond(w, queueErr, util.HttpStatusCodeFromAppError(queueErr))
+			return
+		}
+
+		results := make([]messageBatchResult, len(messages))
+		for i, message := range messages {
+			publishErr := validateBatchMessage(validate, message)
+			if publishErr == nil {
+				message.Exchange = ""
+				publishErr = queue.Enqueue(message)
+			}
+			results[i] = publishedMessageResult(message, publishErr)
+		}
+
+		util.Respond(w, results, http.StatusOK)
+	}
+}
+
+func validateBatchMessage(validate *validator.Validate, message *internal.Message) (err errs.AppError) {
+	if message == nil {
+		return errs.NewParamInvalidError("messages", "Must not contain null messages")
+	}
+
+	message.Id = uuid.New()
+	message.Timestamp = time.Now().UTC()
+
+	var vErrors validator.ValidationErrors
+	if errors.As(validate.Struct(message), &vErrors) {
+		return errs.NewValidationError(vErrors)
+	}
+
+	return nil
+}
+
+func publishedMessageResult(message *internal.Message, err errs.AppError) messageBatchResult {
+	if err != nil {
+		return messageBatchResult{Success: false, Error: err}
+	}
+
+	return messageBatchResult{MessageId: message.Id.String(), Success: true, Duplicate: message.Duplicate}
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+	httputil "github.com/melyouz/risala/broker/internal/http/util"
+	"github.com/melyouz/risala/broker/internal/storage"
+	"github.com/melyouz/risala/broker/internal/testing/util"
+	"github.com/melyouz/risala/broker/internal/validation"
+)
+
+func setupQueueMessagePublishBatchTest(t *testing.T, queues map[string]*internal.Queue, queueName string, messagesBody []byte) (*httptest.ResponseRecorder, *http.Request) {
+	t.Helper()
+
+	queueRepository := storage.NewInMemoryQueueRepository(queues)
+
+	path := fmt.Sprintf("%s/queues/%s/messages/publish-batch", util.ApiV1BasePath, queueName)
+	request := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(messagesBody))
+	response := httptest.NewRecorder()
+
+	routerCtx := chi.NewRouteContext()
+	routerCtx.URLParams.Add("queueName", queueName)
+	request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routerCtx))
+
+	HandleQueueMessagePublishBatch(queueRepository, validation.NewJSONValidator())(response, request)
+
+	return response, request
+}
+
+func TestHandleQueueMessagePublishBatch(t *testing.T) {
+
+	t.Run("Publishes valid messages & reports result per message", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		messagesBody, _ := json.Marshal([]map[string]interface{}{
+			{"payload": "Message 1", "correlationId": "1"},
+			{"payload": ""},
+			{"payload": "Message 3"},
+		})
+
+		response, _ := setupQueueMessagePublishBatchTest(t, queues, "events", messagesBody)
+
+		util.AssertOk(t, response)
+		jsonResponse := util.JSONCollectionResponse(response)
+		assert.Len(t, jsonResponse, 3)
+		assert.Equal(t, true, jsonResponse[0]["success"])
+		assert.Equal(t, queues["events"].GetMessages()[0].Id.String(), jsonResponse[0]["messageId"])
+		assert.Equal(t, false, jsonResponse[1]["success"])
+		assert.Nil(t, jsonResponse[1]["messageId"])
+		assert.Equal(t, map[string]interface{}{
+			"code":   errs.ValidationErrorCode,
+			"errors": []interface{}{map[string]interface{}{"field": "payload", "message": "This field is required"}},
+		}, jsonResponse[1]["error"])
+		assert.Equal(t, true, jsonResponse[2]["success"])
+		assert.Equal(t, queues["events"].GetMessages()[1].Id.String(), jsonResponse[2]["messageId"])
+		assert.Len(t, queues["events"].GetMessages(), 2)
+		assert.Equal(t, "1", queues["events"].GetMessages()[0].CorrelationId)
+		assert.False(t, queues["events"].GetMessages()[0].Timestamp.IsZero())
+	})
+
+	t.Run("Flags messages published again within the deduplication window", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders": {Name: "orders", Durability: internal.Durability.DURABLE, DeduplicationWindow: 60},
+		}
+		messagesBody, _ := json.Marshal([]map[string]interface{}{
+			{"payload": "Order placed", "deduplicationId": "order-42"},
+			{"payload": "Order placed", "deduplicationId": "order-42"},
+		})
+
+		response, _ := setupQueueMessagePublishBatchTest(t, queues, "orders", messagesBody)
+
+		util.AssertOk(t, response)
+		jsonResponse := util.JSONCollectionResponse(response)
+		assert.Nil(t, jsonResponse[0]["isDuplicate"])
+		assert.Equal(t, true, jsonResponse[1]["isDuplicate"])
+		assert.Equal(t, true, jsonResponse[1]["success"])
+		assert.Equal(t, jsonResponse[0]["messageId"], jsonResponse[1]["messageId"])
+		assert.Len(t, queues["orders"].GetMessages(), 1)
+	})
+
+	t.Run("Returns bad request when no messages supplied", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		messagesBody, _ := json.Marshal([]map[string]interface{}{})
+
+		response, _ := setupQueueMessagePublishBatchTest(t, queues, "events", messagesBody)
+
+		util.AssertBadRequest(t, response, errs.ParamInvalidErrorCode, "Must contain between 1 and 100 messages")
+	})
+
+	t.Run("Returns bad request when too many messages supplied", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		messages := make([]map[string]interface{}, internal.MaxBatchSize+1)
+		for i := range messages {
+			messages[i] = map[string]interface{}{"payload": "Hello"}
+		}
+		messagesBody, _ := json.Marshal(messages)
+
+		response, _ := setupQueueMessagePublishBatchTest(t, queues, "events", messagesBody)
+
+		util.AssertBadRequest(t, response, errs.ParamInvalidErrorCode, "Must contain between 1 and 100 messages")
+		assert.Len(t, queues["events"].GetMessages(), 0)
+	})
+
+	t.Run("Returns payload too large when messages body exceeds the maximum batch size", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		messages := make([]map[string]interface{}, internal.MaxBatchSize)
+		for i := range messages {
+			messages[i] = map[string]interface{}{"payload": strings.Repeat("a", httputil.MaxBatchBodySize/internal.MaxBatchSize)}
+		}
+		messagesBody, _ := json.Marshal(messages)
+
+		response, _ := setupQueueMessagePublishBatchTest(t, queues, "events", messagesBody)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, response.Code)
+		assert.Equal(t, errs.PayloadTooLargeErrorCode, util.JSONItemResponse(response)["code"])
+		assert.Len(t, queues["events"].GetMessages(), 0)
+	})
+
+	t.Run("Returns bad request when a message field has the wrong type", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+
+		response, _ := setupQueueMessagePublishBatchTest(t, queues, "events", []byte(`[{"payload":"Hello"},{"payload":"Later","delay":"10"}]`))
+
+		util.AssertBadRequest(t, response, errs.ParamInvalidErrorCode, "Invalid string value. Must be of type int")
+		assert.Len(t, queues["events"].GetMessages(), 0)
+	})
+
+	t.Run("Returns bad request when messages body is malformed JSON", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+
+		response, _ := setupQueueMessagePublishBatchTest(t, queues, "events", []byte(`[{"payload":"Hello"}`))
+
+		util.AssertBadRequest(t, response, errs.ParamInvalidErrorCode, "Invalid JSON body: unexpected EOF")
+		assert.Len(t, queues["events"].GetMessages(), 0)
+	})
+
+	t.Run("Returns not found when queue does not exist", func(t *testing.T) {
+		messagesBody, _ := json.Marshal([]map[string]interface{}{{"payload": "Hello"}})
+
+		response, _ := setupQueueMessagePublishBatchTest(t, map[string]*internal.Queue{}, "nonExistingQueueName", messagesBody)
+
+		util.AssertNotFound(t, response, "QUEUE_NOT_FOUND", "Queue 'nonExistingQueueName' not found")
+	})
+}
@@ -7,13 +7,17 @@ package handler
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/melyouz/risala/broker/internal"
@@ -21,6 +25,7 @@ import (
 	httputil "github.com/melyouz/risala/broker/internal/http/util"
 	"github.com/melyouz/risala/broker/internal/storage"
 	"github.com/melyouz/risala/broker/internal/testing/util"
+	"github.com/melyouz/risala/broker/internal/validation"
 )
 
 func setupQueueMessagePublishTest(t *testing.T, queues map[string]*internal.Queue, queueName string, messageBody []byte) (*httptest.ResponseRecorder, *http.Request) {
@@ -36,7 +41,34 @@ func setupQueueMessagePublishTest(t *testing.T, queues map[string]*internal.Queu
 	routerCtx.URLParams.Add("queueName", queueName)
 	request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routerCtx))
 
-	HandleQueueMessagePublish(queueRepository, httputil.NewJSONValidator())(response, request)
+	HandleQueueMessagePublish(queueRepository, validation.NewJSONValidator())(response, request)
+
+	return response, request
+}
+
+func setupQueueMessagePublishRawTest(t *testing.T, queues map[string]*internal.Queue, queueName string, body []byte, headers map[string]string) (*httptest.ResponseRecorder, *http.Request) {
+	t.Helper()
+
+	return setupQueueMessagePublishRawWithQueryTest(t, queues, queueName, "", body, headers)
+}
+
+func setupQueueMessagePublishRawWithQueryTest(t *testing.T, queues map[string]*internal.Queue, queueName string, query string, body []byte, headers map[string]string) (*httptest.ResponseRecorder, *http.Request) {
+	t.Helper()
+
+	queueRepository := storage.NewInMemoryQueueRepository(queues)
+
+	path := fmt.Sprintf("%s/queues/%s/messages/publish%s", util.ApiV1BasePath, queueName, query)
+	request := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	response := httptest.NewRecorder()
+
+	routerCtx := chi.NewRouteContext()
+	routerCtx.URLParams.Add("queueName", queueName)
+	request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routerCtx))
+
+	HandleQueueMessagePublish(queueRepository, validation.NewJSONValidator())(response, request)
 
 	return response, request
 }
@@ -62,13 +94,453 @@ func TestHandleQueueMessagePublish(t *testing.T) {
 		assert.Equal(t, "Hello world!", jsonResponse["payload"])
 	})
 
+	t.Run("Publishes message with its metadata", func(t *testing.T) {
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":       `{"productId":"42"}`,
+			"headers":       map[string]string{"type": "product.created"},
+			"contentType":   "application/json",
+			"correlationId": "9d1c4e2a",
+			"replyTo":       "replies",
+			"exchange":      "spoofed",
+			"timestamp":     "2000-01-01T00:00:00Z",
+		})
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "events", messageBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, map[string]interface{}{"type": "product.created"}, jsonResponse["headers"])
+		assert.Equal(t, "application/json", jsonResponse["contentType"])
+		assert.Equal(t, "9d1c4e2a", jsonResponse["correlationId"])
+		assert.Equal(t, "replies", jsonResponse["replyTo"])
+		assert.Nil(t, jsonResponse["exchange"])
+		publishedAt, timeErr := time.Parse(time.RFC3339Nano, jsonResponse["timestamp"].(string))
+		assert.Nil(t, timeErr)
+		assert.WithinDuration(t, time.Now(), publishedAt, time.Minute)
+
+		message := queues["events"].GetMessages()[len(queues["events"].GetMessages())-1]
+		assert.Equal(t, "product.created", message.Headers["type"])
+		assert.Equal(t, "application/json", message.ContentType)
+		assert.Equal(t, "9d1c4e2a", message.CorrelationId)
+		assert.Equal(t, "replies", message.ReplyTo)
+	})
+
+	t.Run("Publishes base64 encoded binary payload", func(t *testing.T) {
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":         base64.StdEncoding.EncodeToString([]byte{0x00, 0xff, 0xfe, 0x01}),
+			"payloadEncoding": "base64",
+			"contentType":     "application/x-protobuf",
+		})
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "events", messageBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, "AP/+AQ==", jsonResponse["payload"])
+		assert.Equal(t, "base64", jsonResponse["payloadEncoding"])
+
+		message := queues["events"].GetMessages()[len(queues["events"].GetMessages())-1]
+		assert.Equal(t, string([]byte{0x00, 0xff, 0xfe, 0x01}), message.Payload)
+	})
+
+	t.Run("Publishes raw body with its content type", func(t *testing.T) {
+		body := []byte{0x0a, 0x02, 0x34, 0x32, 0xff}
+
+		response, _ := setupQueueMessagePublishRawTest(t, queues, "events", body, map[string]string{
+			"Content-Type":     "application/x-protobuf",
+			"X-Routing-Key":    "product.created",
+			"X-Correlation-Id": "9d1c4e2a",
+			"X-Reply-To":       "replies",
+			"X-Header-Type":    "product.created",
+		})
+
+		util.AssertCreated(t, response)
+		message := queues["events"].GetMessages()[len(queues["events"].GetMessages())-1]
+		assert.Equal(t, string(body), message.Payload)
+		assert.Equal(t, "application/x-protobuf", message.ContentType)
+		assert.Equal(t, "product.created", message.RoutingKey)
+		assert.Equal(t, "9d1c4e2a", message.CorrelationId)
+		assert.Equal(t, "replies", message.ReplyTo)
+		assert.Equal(t, map[string]string{"type": "product.created"}, message.Headers)
+	})
+
+	t.Run("Publishes JSON raw body when requested", func(t *testing.T) {
+		body := []byte(`{"payload":"Hello world!","isProcessing":true}`)
+
+		response, _ := setupQueueMessagePublishRawWithQueryTest(t, queues, "events", "?raw=true", body, map[string]string{
+			"Content-Type":  "application/json",
+			"X-Routing-Key": "product.created",
+		})
+
+		util.AssertCreated(t, response)
+		message := queues["events"].GetMessages()[len(queues["events"].GetMessages())-1]
+		assert.Equal(t, string(body), message.Payload)
+		assert.Equal(t, "application/json", message.ContentType)
+		assert.Equal(t, "product.created", message.RoutingKey)
+	})
+
+	t.Run("Returns bad request when raw query param is invalid", func(t *testing.T) {
+		response, _ := setupQueueMessagePublishRawWithQueryTest(t, queues, "events", "?raw=maybe", []byte(`{"payload":"Hello world!"}`), map[string]string{})
+
+		util.AssertBadRequest(t, response, "INVALID_PARAM", "Invalid value 'maybe'. Must be one of: true false")
+	})
+
+	t.Run("Returns payload too large when raw body exceeds the maximum payload size", func(t *testing.T) {
+		messagesCount := len(queues["events"].GetMessages())
+		body := bytes.Repeat([]byte{0xff}, httputil.MaxPayloadSize+1)
+
+		response, _ := setupQueueMessagePublishRawTest(t, queues, "events", body, map[string]string{
+			"Content-Type": "application/octet-stream",
+		})
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, response.Code)
+		assert.Equal(t, errs.PayloadTooLargeErrorCode, util.JSONItemResponse(response)["code"])
+		assert.Len(t, queues["events"].GetMessages(), messagesCount)
+	})
+
+	t.Run("Returns payload too large when JSON body exceeds the maximum message size", func(t *testing.T) {
+		messagesCount := len(queues["events"].GetMessages())
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload": strings.Repeat("a", httputil.MaxMessageBodySize),
+		})
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "events", messageBody)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, response.Code)
+		assert.Equal(t, errs.PayloadTooLargeErrorCode, util.JSONItemResponse(response)["code"])
+		assert.Len(t, queues["events"].GetMessages(), messagesCount)
+	})
+
+	t.Run("Returns payload too large when base64 payload exceeds the maximum payload size", func(t *testing.T) {
+		messagesCount := len(queues["events"].GetMessages())
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":         base64.StdEncoding.EncodeToString(make([]byte, httputil.MaxPayloadSize+1)),
+			"payloadEncoding": "base64",
+		})
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "events", messageBody)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, response.Code)
+		assert.Equal(t, errs.PayloadTooLargeErrorCode, util.JSONItemResponse(response)["code"])
+		assert.Len(t, queues["events"].GetMessages(), messagesCount)
+	})
+
+	t.Run("Ignores message fields set by the broker", func(t *testing.T) {
+		id := uuid.New()
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"id":            id.String(),
+			"payload":       "Hello world!",
+			"isProcessing":  true,
+			"deliveryCount": 3,
+			"expiresAt":     time.Now().Add(-time.Hour),
+			"isDelayed":     true,
+			"isDuplicate":   true,
+		})
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "tmp", messageBody)
+
+		util.AssertCreated(t, response)
+		message := queues["tmp"].GetMessages()[len(queues["tmp"].GetMessages())-1]
+		assert.NotEqual(t, id, message.Id)
+		assert.Equal(t, "Hello world!", message.Payload)
+		assert.False(t, message.IsProcessing())
+		assert.Equal(t, 0, message.DeliveryCount)
+		assert.Nil(t, message.ExpiresAt)
+		assert.False(t, message.Delayed)
+		assert.False(t, message.Duplicate)
+	})
+
+	t.Run("Returns validation error when raw body is empty", func(t *testing.T) {
+		response, _ := setupQueueMessagePublishRawTest(t, queues, "events", []byte{}, map[string]string{
+			"Content-Type": "application/octet-stream",
+		})
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "payload", Message: "This field is required"},
+		})
+	})
+
+	t.Run("Returns bad request when a message field has the wrong type", func(t *testing.T) {
+		response, _ := setupQueueMessagePublishTest(t, queues, "events", []byte(`{"payload":"Hello world!","delay":"10"}`))
+
+		util.AssertBadRequest(t, response, "INVALID_PARAM", "Invalid string value. Must be of type int")
+		assert.Equal(t, "delay", util.JSONItemResponse(response)["param"])
+	})
+
+	t.Run("Returns bad request when message deliver at is not a time", func(t *testing.T) {
+		initialMessageCount := len(queues["events"].GetMessages())
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "events", []byte(`{"payload":"Hello world!","deliverAt":"tomorrow"}`))
+
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Equal(t, "INVALID_PARAM", util.JSONItemResponse(response)["code"])
+		assert.Len(t, queues["events"].GetMessages(), initialMessageCount)
+	})
+
+	t.Run("Returns bad request when message body is malformed JSON", func(t *testing.T) {
+		response, _ := setupQueueMessagePublishTest(t, queues, "events", []byte(`{"payload":"Hello world!",`))
+
+		util.AssertBadRequest(t, response, "INVALID_PARAM", "Invalid JSON body: unexpected EOF")
+		assert.Equal(t, "body", util.JSONItemResponse(response)["param"])
+	})
+
+	t.Run("Returns bad request when payload encoding is invalid", func(t *testing.T) {
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":         "Hello world!",
+			"payloadEncoding": "hex",
+		})
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "events", messageBody)
+
+		util.AssertBadRequest(t, response, errs.ParamInvalidErrorCode, "Invalid value 'hex' for 'payloadEncoding'. Must be one of: base64")
+	})
+
+	t.Run("Returns bad request when base64 payload is malformed", func(t *testing.T) {
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":         "not base64!",
+			"payloadEncoding": "base64",
+		})
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "events", messageBody)
+
+		util.AssertBadRequest(t, response, errs.ParamInvalidErrorCode, "Invalid base64 payload")
+	})
+
+	t.Run("Publishes message with its expiration", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": {Name: "events", Durability: internal.Durability.DURABLE, MessageTtl: 60},
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":    "Hello world!",
+			"expiration": 10,
+			"expiresAt":  "2000-01-01T00:00:00Z",
+		})
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "events", messageBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, float64(10), jsonResponse["expiration"])
+		expiresAt, parseErr := time.Parse(time.RFC3339Nano, jsonResponse["expiresAt"].(string))
+		assert.Nil(t, parseErr)
+		assert.WithinDuration(t, time.Now().Add(10*time.Second), expiresAt, time.Second)
+	})
+
+	t.Run("Publishes message expiring after the queue message TTL", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": {Name: "events", Durability: internal.Durability.DURABLE, MessageTtl: 60},
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":    "Hello world!",
+			"expiration": 3600,
+		})
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "events", messageBody)
+
+		util.AssertCreated(t, response)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), *queues["events"].GetMessages()[0].ExpiresAt, time.Second)
+	})
+
+	t.Run("Returns validation error when message expiration is negative", func(t *testing.T) {
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":    "Hello world!",
+			"expiration": -1,
+		})
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "tmp", messageBody)
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "expiration", Message: "Invalid value '-1'. Must be greater than or equal to 0"},
+		})
+	})
+
+	t.Run("Publishes message with its priority", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders": {Name: "orders", Durability: internal.Durability.DURABLE, MaxPriority: 10},
+		}
+		_ = queues["orders"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Catalog updated"})
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":  "Order cancelled",
+			"priority": 5,
+		})
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "orders", messageBody)
+
+		util.AssertCreated(t, response)
+		assert.Equal(t, float64(5), util.JSONItemResponse(response)["priority"])
+		assert.Equal(t, "Order cancelled", queues["orders"].GetMessages()[0].Payload)
+	})
+
+	t.Run("Publishes raw body with its priority", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders": {Name: "orders", Durability: internal.Durability.DURABLE, MaxPriority: 10},
+		}
+
+		response, _ := setupQueueMessagePublishRawTest(t, queues, "orders", []byte("Order cancelled"), map[string]string{
+			"Content-Type": "text/plain",
+			"X-Priority":   "5",
+		})
+
+		util.AssertCreated(t, response)
+		assert.Equal(t, 5, queues["orders"].GetMessages()[0].Priority)
+	})
+
+	t.Run("Returns validation error when message priority is out of range", func(t *testing.T) {
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":  "Hello world!",
+			"priority": 256,
+		})
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "tmp", messageBody)
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "priority", Message: "Invalid value '256'. Must be less than or equal to 255"},
+		})
+	})
+
+	t.Run("Publishes delayed message", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"reminders": util.NewTestQueueDurableWithoutMessages("reminders"),
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload": "Renew subscription",
+			"delay":   3600,
+		})
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "reminders", messageBody)
+
+		util.AssertCreated(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, true, jsonResponse["isDelayed"])
+		deliverAt, parseErr := time.Parse(time.RFC3339Nano, jsonResponse["deliverAt"].(string))
+		assert.Nil(t, parseErr)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), deliverAt, time.Second)
+		assert.Nil(t, queues["reminders"].Dequeue())
+	})
+
+	t.Run("Publishes raw body delivered at the requested time", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"reminders": util.NewTestQueueDurableWithoutMessages("reminders"),
+		}
+
+		response, _ := setupQueueMessagePublishRawTest(t, queues, "reminders", []byte("Renew subscription"), map[string]string{
+			"Content-Type": "text/plain",
+			"X-Deliver-At": "2100-01-01T00:00:00Z",
+		})
+
+		util.AssertCreated(t, response)
+		messages, _ := queues["reminders"].Peek(1)
+		assert.Equal(t, time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC), *messages[0].DeliverAt)
+		assert.True(t, messages[0].Delayed)
+	})
+
+	t.Run("Returns validation error when both message delay & deliver at are set", func(t *testing.T) {
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":   "Hello world!",
+			"delay":     60,
+			"deliverAt": "2100-01-01T00:00:00Z",
+		})
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "tmp", messageBody)
+
+		util.AssertValidationErrors(t, response, []errs.ValidationError{
+			{Field: "deliverAt", Message: "Must not be set together with delay"},
+		})
+	})
+
+	t.Run("Returns original message when published again within the deduplication window", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders": {Name: "orders", Durability: internal.Durability.DURABLE, DeduplicationWindow: 60},
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload":         "Order placed",
+			"deduplicationId": "order-42",
+		})
+		originalResponse, _ := setupQueueMessagePublishTest(t, queues, "orders", messageBody)
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "orders", messageBody)
+
+		util.AssertCreated(t, originalResponse)
+		util.AssertOk(t, response)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, util.JSONItemResponse(originalResponse)["id"], jsonResponse["id"])
+		assert.Equal(t, true, jsonResponse["isDuplicate"])
+		assert.Len(t, queues["orders"].GetMessages(), 1)
+	})
+
+	t.Run("Publishes raw body with its deduplication id", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"orders": {Name: "orders", Durability: internal.Durability.DURABLE, DeduplicationWindow: 60},
+		}
+		headers := map[string]string{
+			"Content-Type":       "text/plain",
+			"X-Deduplication-Id": "order-42",
+		}
+		_, _ = setupQueueMessagePublishRawTest(t, queues, "orders", []byte("Order placed"), headers)
+
+		response, _ := setupQueueMessagePublishRawTest(t, queues, "orders", []byte("Order placed"), headers)
+
+		util.AssertOk(t, response)
+		assert.Len(t, queues["orders"].GetMessages(), 1)
+		assert.Equal(t, "order-42", queues["orders"].GetMessages()[0].DeduplicationId)
+	})
+
+	t.Run("Publishes message with its group id", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload": "Product updated",
+			"groupId": "product-1",
+		})
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "events", messageBody)
+
+		util.AssertCreated(t, response)
+		assert.Equal(t, "product-1", util.JSONItemResponse(response)["groupId"])
+		assert.Equal(t, "product-1", queues["events"].GetMessages()[0].GroupId)
+	})
+
+	t.Run("Publishes raw body with its group id", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		headers := map[string]string{
+			"Content-Type": "text/plain",
+			"X-Group-Id":   "product-1",
+		}
+
+		response, _ := setupQueueMessagePublishRawTest(t, queues, "events", []byte("Product updated"), headers)
+
+		util.AssertCreated(t, response)
+		assert.Equal(t, "product-1", queues["events"].GetMessages()[0].GroupId)
+	})
+
+	t.Run("Returns too many requests when queue is full", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": {Name: "events", Durability: internal.Durability.DURABLE, MaxLength: 1, Overflow: internal.OverflowPolicies.REJECT_PUBLISH},
+		}
+		_ = queues["events"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Hello world!"})
+		messageBody, _ := json.Marshal(map[string]interface{}{
+			"payload": "Hello again!",
+		})
+
+		response, _ := setupQueueMessagePublishTest(t, queues, "events", messageBody)
+
+		assert.Equal(t, http.StatusTooManyRequests, response.Code)
+		jsonResponse := util.JSONItemResponse(response)
+		assert.Equal(t, errs.QueueFullErrorCode, jsonResponse["code"])
+		assert.Equal(t, "Queue 'events' is full", jsonResponse["message"])
+		assert.Len(t, queues["events"].GetMessages(), 1)
+	})
+
 	t.Run("Returns validation error when no message payload supplied", func(t *testing.T) {
 		messageBody, _ := json.Marshal(map[string]interface{}{})
 
 		response, _ := setupQueueMessagePublishTest(t, queues, "tmp", messageBody)
 
 		util.AssertValidationErrors(t, response, []errs.ValidationError{
-			{"payload", "This field is required"},
+			{Field: "payload", Message: "This field is required"},
 		})
 	})
 
@@ -80,7 +552,7 @@ func TestHandleQueueMessagePublish(t *testing.T) {
 		response, _ := setupQueueMessagePublishTest(t, queues, "tmp", messageBody)
 
 		util.AssertValidationErrors(t, response, []errs.ValidationError{
-			{"payload", "This field is required"},
+			{Field: "payload", Message: "This field is required"},
 		})
 	})
 
@@ -92,7 +564,7 @@ func TestHandleQueueMessagePublish(t *testing.T) {
 		response, _ := setupQueueMessagePublishTest(t, queues, "tmp", messageBody)
 
 		util.AssertValidationErrors(t, response, []errs.ValidationError{
-			{"payload", "This field is required"},
+			{Field: "payload", Message: "This field is required"},
 		})
 	})
 
@@ -53,13 +53,13 @@ func TestHandleQueueMessagePurge(t *testing.T) {
 			{Id: uuid.New(), Payload: "Message 4"},
 			{Id: uuid.New(), Payload: "Message 5"},
 		}
-		queues["events"].Messages = messages
+		queues["events"].RestoreMessages(messages)
 
 		response, _ := setupQueueMessagePurgeTest(t, queues, "events")
 
 		util.AssertNoContent(t, response)
 		assert.Empty(t, response.Body)
-		assert.Empty(t, queues["events"].Messages)
+		assert.Empty(t, queues["events"].GetMessages())
 	})
 
 	t.Run("Returns not found when queue does not exist", func(t *testing.T) {
@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+	"github.com/melyouz/risala/broker/internal/http/util"
+	"github.com/melyouz/risala/broker/internal/storage"
+)
+
+const subscribeHeartbeatInterval = 15 * time.Second
+
+func HandleQueueMessageSubscribe(queueRepository storage.QueueRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		prefetchParamName := "prefetch"
+		prefetch, paramErr := util.IntQueryParam(r, prefetchParamName, internal.DefaultPrefetch)
+		if paramErr == nil && (prefetch < 1 || prefetch > internal.MaxPrefetch) {
+			paramErr = errs.NewParamInvalidError(prefetchParamName, fmt.Sprintf("Must be between 1 and %d", internal.MaxPrefetch))
+		}
+		if paramErr != nil {
+			util.Respond(w, paramErr, util.HttpStatusCodeFromAppError(paramErr))
+			return
+		}
+
+		visibilityTimeoutParamName := "visibilityTimeout"
+		visibilityTimeout, paramErr := util.DurationQueryParam(r, visibilityTimeoutParamName)
+		if paramErr == nil && visibilityTimeout > internal.MaxVisibilityTimeout {
+			paramErr = errs.NewParamInvalidError(visibilityTimeoutParamName, fmt.Sprintf("Must not exceed %s", internal.MaxVisibilityTimeout))
+		}
+		if paramErr != nil {
+			util.Respond(w, paramErr, util.HttpStatusCodeFromAppError(paramErr))
+			return
+		}
+
+		queueName := chi.URLParam(r, "queueName")
+		queue, queueErr := queueRepository.GetQueue(queueName)
+		if queueErr != nil {
+			util.Respond(w, queueErr, util.HttpStatusCodeFromAppError(queueErr))
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		_ = rc.SetWriteDeadline(time.Time{})
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if flushErr := rc.Flush(); flushErr != nil {
+			return
+		}
+
+		subscription := internal.NewSubscription(queue, prefetch, visibilityTimeout)
+		defer subscription.Close()
+		for {
+			message := subscription.Next(r.Context(), subscribeHeartbeatInterval)
+			if r.Context().Err() != nil {
+				return
+			}
+
+			var writeErr error
+			if message == nil {
+				_, writeErr = fmt.Fprint(w, ": heartbeat\n\n")
+			} else {
+				writeErr = writeMessageEvent(w, message)
+			}
+			if writeErr == nil {
+				writeErr = rc.Flush()
+			}
+			if writeErr != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeMessageEvent(w http.ResponseWriter, message *internal.Message) error {
+	data, encodeErr := json.Marshal(message)
+	if encodeErr != nil {
+		return encodeErr
+	}
+
+	_, writeErr := fmt.Fprintf(w, "id: %s\nevent: message\ndata: %s\n\n", message.Id, data)
+
+	return writeErr
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/storage"
+	"github.com/melyouz/risala/broker/internal/testing/util"
+)
+
+func setupQueueMessageSubscribeTest(t *testing.T, queues map[string]*internal.Queue, queueName string, query string) *http.Response {
+	t.Helper()
+
+	queueRepository := storage.NewInMemoryQueueRepository(queues)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routerCtx := chi.NewRouteContext()
+		routerCtx.URLParams.Add("queueName", queueName)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routerCtx))
+
+		HandleQueueMessageSubscribe(queueRepository)(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	path := fmt.Sprintf("%s%s/queues/%s/messages/subscribe?%s", server.URL, util.ApiV1BasePath, queueName, query)
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	response, err := http.DefaultClient.Do(request)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		cancel()
+		_ = response.Body.Close()
+	})
+
+	return response
+}
+
+func readMessageEvent(t *testing.T, scanner *bufio.Scanner) map[string]interface{} {
+	t.Helper()
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if data, found := strings.CutPrefix(line, "data: "); found {
+			var message map[string]interface{}
+			assert.Nil(t, json.Unmarshal([]byte(data), &message))
+			return message
+		}
+	}
+
+	t.Fatal("Stream ended before a message event was received")
+	return nil
+}
+
+func TestHandleQueueMessageSubscribe(t *testing.T) {
+
+	t.Run("Streams messages as they are enqueued", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		_ = queues["events"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 1"})
+
+		response := setupQueueMessageSubscribeTest(t, queues, "events", "prefetch=2")
+
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+		scanner := bufio.NewScanner(response.Body)
+		first := readMessageEvent(t, scanner)
+		assert.Equal(t, "Message 1", first["payload"])
+		assert.Equal(t, true, first["isProcessing"])
+
+		_ = queues["events"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 2"})
+
+		second := readMessageEvent(t, scanner)
+		assert.Equal(t, "Message 2", second["payload"])
+	})
+
+	t.Run("Streams next message only once unacknowledged messages fit the prefetch", func(t *testing.T) {
+		firstId := uuid.New()
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+		_ = queues["events"].Enqueue(&internal.Message{Id: firstId, Payload: "Message 1"})
+		_ = queues["events"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 2"})
+
+		response := setupQueueMessageSubscribeTest(t, queues, "events", "prefetch=1")
+
+		scanner := bufio.NewScanner(response.Body)
+		first := readMessageEvent(t, scanner)
+		assert.Equal(t, firstId.String(), first["id"])
+		time.Sleep(100 * time.Millisecond)
+		assert.False(t, queues["events"].GetMessages()[1].IsProcessing())
+
+		assert.Nil(t, queues["events"].Ack(firstId))
+
+		second := readMessageEvent(t, scanner)
+		assert.Equal(t, "Message 2", second["payload"])
+	})
+
+	t.Run("Returns bad request when prefetch is out of range", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": util.NewTestQueueDurableWithoutMessages("events"),
+		}
+
+		for _, prefetch := range []string{"-1", "0", "1001"} {
+			response := setupQueueMessageSubscribeTest(t, queues, "events", "prefetch="+prefetch)
+
+			assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		}
+	})
+
+	t.Run("Returns not found when queue does not exist", func(t *testing.T) {
+		response := setupQueueMessageSubscribeTest(t, map[string]*internal.Queue{}, "nonExistingQueueName", "")
+
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+}
@@ -26,22 +26,28 @@ func (s *Server) RegisterRoutes() {
 	queuesRouter.Get("/{queueName}", handler.HandleQueueGet(s.queueRepository))
 	queuesRouter.Delete("/{queueName}", handler.HandleQueueDelete(s.queueRepository))
 	queuesRouter.Post("/{queueName}/messages/publish", handler.HandleQueueMessagePublish(s.queueRepository, s.validate))
+	queuesRouter.Post("/{queueName}/messages/publish-batch", handler.HandleQueueMessagePublishBatch(s.queueRepository, s.validate))
 	queuesRouter.Get("/{queueName}/messages/peek", handler.HandleQueueMessagePeek(s.queueRepository))
 	queuesRouter.Post("/{queueName}/messages/consume", handler.HandleQueueMessageConsume(s.queueRepository))
 	queuesRouter.Post("/{queueName}/messages/purge", handler.HandleQueueMessagePurge(s.queueRepository))
 	queuesRouter.Post("/{queueName}/messages/get", handler.HandleQueueMessageGet(s.queueRepository))
+	queuesRouter.Get("/{queueName}/messages/subscribe", handler.HandleQueueMessageSubscribe(s.queueRepository))
+	queuesRouter.Post("/{queueName}/messages/batch/get", handler.HandleQueueMessageBatchGet(s.queueRepository))
+	queuesRouter.Post("/{queueName}/messages/batch/ack", handler.HandleQueueMessageBatchAck(s.queueRepository, s.validate))
+	queuesRouter.Post("/{queueName}/messages/batch/nack", handler.HandleQueueMessageBatchNack(s.queueRepository, s.exchangeRepository, s.validate))
 	queuesRouter.Post("/{queueName}/messages/{messageId}/ack", handler.HandleQueueMessageAck(s.queueRepository))
-	queuesRouter.Post("/{queueName}/messages/{messageId}/nack", handler.HandleQueueMessageNack(s.queueRepository))
+	queuesRouter.Post("/{queueName}/messages/{messageId}/nack", handler.HandleQueueMessageNack(s.queueRepository, s.exchangeRepository))
 
 	// exchanges
 	exchangesRouter := chi.NewRouter()
-	exchangesRouter.Post("/", handler.HandleExchangeCreate(s.exchangeRepository, s.validate))
+	exchangesRouter.Post("/", handler.HandleExchangeCreate(s.exchangeRepository, s.queueRepository, s.validate))
 	exchangesRouter.Get("/", handler.HandleExchangeFind(s.exchangeRepository))
 	exchangesRouter.Get("/{exchangeName}", handler.HandleExchangeGet(s.exchangeRepository))
 	exchangesRouter.Delete("/{exchangeName}", handler.HandleExchangeDelete(s.exchangeRepository))
 	exchangesRouter.Post("/{exchangeName}/bindings", handler.HandleExchangeBindingAdd(s.exchangeRepository, s.queueRepository, s.validate))
 	exchangesRouter.Delete("/{exchangeName}/bindings/{bindingId}", handler.HandleExchangeBindingDelete(s.exchangeRepository))
 	exchangesRouter.Post("/{exchangeName}/messages/publish", handler.HandleExchangeMessagePublish(s.exchangeRepository, s.queueRepository, s.validate))
+	exchangesRouter.Post("/{exchangeName}/messages/publish-batch", handler.HandleExchangeMessagePublishBatch(s.exchangeRepository, s.queueRepository, s.validate))
 
 	// v1 routes group
 	s.router.Route(ApiV1BasePath, func(r chi.Router) {
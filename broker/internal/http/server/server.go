@@ -11,8 +11,8 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 
-	"github.com/melyouz/risala/broker/internal/http/util"
 	"github.com/melyouz/risala/broker/internal/storage"
+	"github.com/melyouz/risala/broker/internal/validation"
 )
 
 type Server struct {
@@ -32,7 +32,7 @@ func NewServer(
 	s := &Server{
 		listenAddr:         listenAddr,
 		router:             router,
-		validate:           util.NewJSONValidator(),
+		validate:           validation.NewJSONValidator(),
 		queueRepository:    queuesRepository,
 		exchangeRepository: exchangesRepository,
 	}
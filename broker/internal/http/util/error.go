@@ -17,10 +17,15 @@ var httpStatusCodes = map[string]int{
 	errs.QueueNotFoundErrorCode:     http.StatusNotFound,
 	errs.QueueExistsErrorCode:       http.StatusConflict,
 	errs.QueueNonDeletableErrorCode: http.StatusConflict,
+	errs.QueueFullErrorCode:         http.StatusTooManyRequests,
 	errs.MessageNotFoundErrorCode:   http.StatusNotFound,
+	errs.MessageUnroutableErrorCode: http.StatusUnprocessableEntity,
 	errs.BindingNotFoundErrorCode:   http.StatusNotFound,
 	errs.BindingExistsErrorCode:     http.StatusConflict,
+	errs.BindingCycleErrorCode:      http.StatusConflict,
 	errs.ParamInvalidErrorCode:      http.StatusBadRequest,
+	errs.PayloadTooLargeErrorCode:   http.StatusRequestEntityTooLarge,
+	errs.NotAcceptableErrorCode:     http.StatusNotAcceptable,
 }
 
 func HttpStatusCodeFromAppError(err errs.AppError) int {
@@ -0,0 +1,288 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+)
+
+const jsonMediaType = "application/json"
+const defaultRawContentType = "application/octet-stream"
+
+const MessageIdHeader = "X-Message-Id"
+const MessageRoutingKeyHeader = "X-Routing-Key"
+const MessageExchangeHeader = "X-Exchange"
+const MessageCorrelationIdHeader = "X-Correlation-Id"
+const MessageReplyToHeader = "X-Reply-To"
+const MessageTimestampHeader = "X-Timestamp"
+const MessageDeliveryCountHeader = "X-Delivery-Count"
+const MessageExpirationHeader = "X-Expiration"
+const MessageExpiresAtHeader = "X-Expires-At"
+const MessagePriorityHeader = "X-Priority"
+const MessageDelayHeader = "X-Delay"
+const MessageDeliverAtHeader = "X-Deliver-At"
+const MessageDeduplicationIdHeader = "X-Deduplication-Id"
+const MessageGroupIdHeader = "X-Group-Id"
+const MessageHeaderPrefix = "X-Header-"
+
+// MaxPayloadSize is the maximum size in bytes of a message payload, once decoded
+const MaxPayloadSize = 1 << 20
+
+// MaxMessageBodySize is the maximum size in bytes of a JSON message request body, leaving room for the base64 encoding
+// of the payload & the message metadata
+const MaxMessageBodySize = 2 * MaxPayloadSize
+
+// MaxBatchBodySize is the maximum size in bytes of a JSON message batch request body
+const MaxBatchBodySize = 8 * MaxMessageBodySize
+
+// MessageRawQueryParam forces the request body to be published as the payload, even when it is JSON
+const MessageRawQueryParam = "raw"
+
+// messageRequest is the JSON envelope messages are published with, leaving out the fields set by the broker
+type messageRequest struct {
+	Payload         string            `json:"payload"`
+	PayloadEncoding string            `json:"payloadEncoding"`
+	RoutingKey      string            `json:"routingKey"`
+	Headers         map[string]string `json:"headers"`
+	ContentType     string            `json:"contentType"`
+	CorrelationId   string            `json:"correlationId"`
+	ReplyTo         string            `json:"replyTo"`
+	Expiration      int               `json:"expiration"`
+	Priority        int               `json:"priority"`
+	Delay           int               `json:"delay"`
+	DeliverAt       *time.Time        `json:"deliverAt"`
+	DeduplicationId string            `json:"deduplicationId"`
+	GroupId         string            `json:"groupId"`
+}
+
+func (m *messageRequest) decodeInto(message *internal.Message) (err errs.AppError) {
+	payload, err := internal.DecodePayload(m.Payload, m.PayloadEncoding)
+	if err != nil {
+		return err
+	}
+	if len(payload) > MaxPayloadSize {
+		return payloadTooLargeError()
+	}
+
+	message.Payload = payload
+	message.RoutingKey = m.RoutingKey
+	message.Headers = m.Headers
+	message.ContentType = m.ContentType
+	message.CorrelationId = m.CorrelationId
+	message.ReplyTo = m.ReplyTo
+	message.Expiration = m.Expiration
+	message.Priority = m.Priority
+	message.Delay = m.Delay
+	message.DeliverAt = m.DeliverAt
+	message.DeduplicationId = m.DeduplicationId
+	message.GroupId = m.GroupId
+
+	return nil
+}
+
+// DecodeMessage decodes a message from its JSON envelope, or from a raw body along with its metadata headers when the
+// body isn't JSON or the raw query param is set
+func DecodeMessage(r *http.Request, message *internal.Message) (err errs.AppError) {
+	raw, err := BoolQueryParam(r, MessageRawQueryParam)
+	if err != nil {
+		return err
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if !raw && isJSONMediaType(contentType) {
+		var request messageRequest
+		if decodeErr := json.NewDecoder(http.MaxBytesReader(nil, r.Body, MaxMessageBodySize)).Decode(&request); decodeErr != nil {
+			return bodyDecodeError(decodeErr)
+		}
+
+		return request.decodeInto(message)
+	}
+
+	payload, readErr := io.ReadAll(http.MaxBytesReader(nil, r.Body, MaxPayloadSize))
+	if readErr != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(readErr, &maxBytesErr) {
+			return payloadTooLargeError()
+		}
+		return errs.NewParamInvalidError("payload", "Error reading request body")
+	}
+
+	message.Payload = string(payload)
+	message.ContentType = contentType
+	message.RoutingKey = r.Header.Get(MessageRoutingKeyHeader)
+	message.CorrelationId = r.Header.Get(MessageCorrelationIdHeader)
+	message.ReplyTo = r.Header.Get(MessageReplyToHeader)
+	message.DeduplicationId = r.Header.Get(MessageDeduplicationIdHeader)
+	message.GroupId = r.Header.Get(MessageGroupIdHeader)
+	if expiration := r.Header.Get(MessageExpirationHeader); expiration != "" {
+		value, atoiErr := strconv.Atoi(expiration)
+		if atoiErr != nil {
+			return errs.NewParamInvalidError("expiration", fmt.Sprintf("Invalid integer '%s'", expiration))
+		}
+		message.Expiration = value
+	}
+	if priority := r.Header.Get(MessagePriorityHeader); priority != "" {
+		value, atoiErr := strconv.Atoi(priority)
+		if atoiErr != nil {
+			return errs.NewParamInvalidError("priority", fmt.Sprintf("Invalid integer '%s'", priority))
+		}
+		message.Priority = value
+	}
+	if delay := r.Header.Get(MessageDelayHeader); delay != "" {
+		value, atoiErr := strconv.Atoi(delay)
+		if atoiErr != nil {
+			return errs.NewParamInvalidError("delay", fmt.Sprintf("Invalid integer '%s'", delay))
+		}
+		message.Delay = value
+	}
+	if deliverAt := r.Header.Get(MessageDeliverAtHeader); deliverAt != "" {
+		value, parseErr := time.Parse(time.RFC3339Nano, deliverAt)
+		if parseErr != nil {
+			return errs.NewParamInvalidError("deliverAt", fmt.Sprintf("Invalid RFC 3339 time '%s'", deliverAt))
+		}
+		message.DeliverAt = &value
+	}
+	for name, values := range r.Header {
+		if strings.HasPrefix(name, MessageHeaderPrefix) && len(name) > len(MessageHeaderPrefix) {
+			if message.Headers == nil {
+				message.Headers = map[string]string{}
+			}
+			message.Headers[strings.ToLower(strings.TrimPrefix(name, MessageHeaderPrefix))] = values[0]
+		}
+	}
+
+	return nil
+}
+
+func DecodeMessages(r *http.Request, messages *[]*internal.Message) (err errs.AppError) {
+	var requests []*messageRequest
+	if decodeErr := json.NewDecoder(http.MaxBytesReader(nil, r.Body, MaxBatchBodySize)).Decode(&requests); decodeErr != nil {
+		return bodyDecodeError(decodeErr)
+	}
+
+	if len(requests) == 0 || len(requests) > internal.MaxBatchSize {
+		return errs.NewParamInvalidError("messages", fmt.Sprintf("Must contain between 1 and %d messages", internal.MaxBatchSize))
+	}
+
+	*messages = make([]*internal.Message, len(requests))
+	for i, request := range requests {
+		// null messages are left nil, reported as such for each of them
+		if request == nil {
+			continue
+		}
+		(*messages)[i] = &internal.Message{}
+		if err = request.decodeInto((*messages)[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bodyDecodeError reports a JSON body failing to be decoded, pointing to the field holding a value of the wrong type
+func bodyDecodeError(decodeErr error) errs.AppError {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(decodeErr, &maxBytesErr) {
+		return errs.NewPayloadTooLargeError(fmt.Sprintf("Request body must not exceed %d bytes", maxBytesErr.Limit))
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(decodeErr, &typeErr) && typeErr.Field != "" {
+		return errs.NewParamInvalidError(typeErr.Field, fmt.Sprintf("Invalid %s value. Must be of type %s", typeErr.Value, typeErr.Type))
+	}
+
+	return errs.NewParamInvalidError("body", fmt.Sprintf("Invalid JSON body: %s", decodeErr))
+}
+
+func payloadTooLargeError() errs.AppError {
+	return errs.NewPayloadTooLargeError(fmt.Sprintf("Payload must not exceed %d bytes", MaxPayloadSize))
+}
+
+func AcceptsJSON(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return true
+	}
+
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, _, parseErr := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if parseErr != nil {
+			continue
+		}
+		if mediaType == jsonMediaType || mediaType == "application/*" || mediaType == "*/*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func RespondMessage(w http.ResponseWriter, r *http.Request, message *internal.Message, statusCode int) {
+	if AcceptsJSON(r) {
+		Respond(w, message, statusCode)
+		return
+	}
+
+	contentType := message.ContentType
+	if contentType == "" {
+		contentType = defaultRawContentType
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set(MessageIdHeader, message.Id.String())
+	w.Header().Set(MessageDeliveryCountHeader, strconv.Itoa(message.DeliveryCount))
+	setHeaderIfNotEmpty(w, MessageRoutingKeyHeader, message.RoutingKey)
+	setHeaderIfNotEmpty(w, MessageExchangeHeader, message.Exchange)
+	setHeaderIfNotEmpty(w, MessageCorrelationIdHeader, message.CorrelationId)
+	setHeaderIfNotEmpty(w, MessageReplyToHeader, message.ReplyTo)
+	setHeaderIfNotEmpty(w, MessageDeduplicationIdHeader, message.DeduplicationId)
+	setHeaderIfNotEmpty(w, MessageGroupIdHeader, message.GroupId)
+	if !message.Timestamp.IsZero() {
+		w.Header().Set(MessageTimestampHeader, message.Timestamp.Format(time.RFC3339Nano))
+	}
+	if message.ExpiresAt != nil {
+		w.Header().Set(MessageExpiresAtHeader, message.ExpiresAt.Format(time.RFC3339Nano))
+	}
+	if message.Priority > 0 {
+		w.Header().Set(MessagePriorityHeader, strconv.Itoa(message.Priority))
+	}
+	if message.DeliverAt != nil {
+		w.Header().Set(MessageDeliverAtHeader, message.DeliverAt.Format(time.RFC3339Nano))
+	}
+	for name, value := range message.Headers {
+		w.Header().Set(MessageHeaderPrefix+name, value)
+	}
+
+	w.WriteHeader(statusCode)
+	_, _ = w.Write([]byte(message.Payload))
+}
+
+func isJSONMediaType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, parseErr := mime.ParseMediaType(contentType)
+
+	return parseErr == nil && mediaType == jsonMediaType
+}
+
+func setHeaderIfNotEmpty(w http.ResponseWriter, name string, value string) {
+	if value != "" {
+		w.Header().Set(name, value)
+	}
+}
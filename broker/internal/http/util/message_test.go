@@ -0,0 +1,39 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcceptsJSON(t *testing.T) {
+	testCases := []struct {
+		accept   string
+		expected bool
+	}{
+		{"", true},
+		{"application/json", true},
+		{"application/json; charset=utf-8", true},
+		{"*/*", true},
+		{"application/*", true},
+		{"application/octet-stream, application/json;q=0.5", true},
+		{"application/octet-stream", false},
+		{"text/plain", false},
+		{"application/x-protobuf", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.accept, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("Accept", tc.accept)
+
+			assert.Equal(t, tc.expected, AcceptsJSON(request))
+		})
+	}
+}
@@ -6,9 +6,65 @@ package util
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/melyouz/risala/broker/internal/errs"
 )
 
 func Decode(r *http.Request, dst interface{}) {
 	_ = json.NewDecoder(r.Body).Decode(&dst)
 }
+
+func DurationQueryParam(r *http.Request, name string) (duration time.Duration, err errs.AppError) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	if seconds, atoiErr := strconv.Atoi(value); atoiErr == nil {
+		duration = time.Duration(seconds) * time.Second
+	} else {
+		parsedDuration, parseErr := time.ParseDuration(value)
+		if parseErr != nil {
+			return 0, errs.NewParamInvalidError(name, fmt.Sprintf("Invalid duration '%s' (e.g. 30 or 30s)", value))
+		}
+		duration = parsedDuration
+	}
+
+	if duration < 0 {
+		return 0, errs.NewParamInvalidError(name, fmt.Sprintf("Invalid duration '%s'. Must not be negative", value))
+	}
+
+	return duration, nil
+}
+
+func BoolQueryParam(r *http.Request, name string) (value bool, err errs.AppError) {
+	rawValue := r.URL.Query().Get(name)
+	if rawValue == "" {
+		return false, nil
+	}
+
+	value, parseErr := strconv.ParseBool(rawValue)
+	if parseErr != nil {
+		return false, errs.NewParamInvalidError(name, fmt.Sprintf("Invalid value '%s'. Must be one of: true false", rawValue))
+	}
+
+	return value, nil
+}
+
+func IntQueryParam(r *http.Request, name string, defaultValue int) (value int, err errs.AppError) {
+	rawValue := r.URL.Query().Get(name)
+	if rawValue == "" {
+		return defaultValue, nil
+	}
+
+	value, atoiErr := strconv.Atoi(rawValue)
+	if atoiErr != nil {
+		return 0, errs.NewParamInvalidError(name, fmt.Sprintf("Invalid integer '%s'", rawValue))
+	}
+
+	return value, nil
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -43,3 +44,95 @@ func TestDecode(t *testing.T) {
 		assert.Equal(t, "Test content 2", entity.Children[1].Content)
 	})
 }
+
+func TestDurationQueryParam(t *testing.T) {
+	t.Run("Parses duration query param", func(t *testing.T) {
+		testCases := map[string]time.Duration{
+			"":      0,
+			"0":     0,
+			"30":    30 * time.Second,
+			"30s":   30 * time.Second,
+			"1m30s": 90 * time.Second,
+			"250ms": 250 * time.Millisecond,
+		}
+
+		for value, expected := range testCases {
+			request := httptest.NewRequest(http.MethodPost, "/?timeout="+value, nil)
+
+			duration, err := DurationQueryParam(request, "timeout")
+
+			assert.Nil(t, err)
+			assert.Equal(t, expected, duration)
+		}
+	})
+
+	t.Run("Returns invalid param error when duration is invalid", func(t *testing.T) {
+		for _, value := range []string{"whatever", "-5", "-5s"} {
+			request := httptest.NewRequest(http.MethodPost, "/?timeout="+value, nil)
+
+			_, err := DurationQueryParam(request, "timeout")
+
+			assert.NotNil(t, err)
+			assert.Equal(t, "INVALID_PARAM", err.GetCode())
+		}
+	})
+}
+
+func TestBoolQueryParam(t *testing.T) {
+	t.Run("Parses bool query param", func(t *testing.T) {
+		testCases := map[string]bool{
+			"":      false,
+			"false": false,
+			"0":     false,
+			"true":  true,
+			"1":     true,
+		}
+
+		for value, expected := range testCases {
+			request := httptest.NewRequest(http.MethodPost, "/?flag="+value, nil)
+
+			flag, err := BoolQueryParam(request, "flag")
+
+			assert.Nil(t, err)
+			assert.Equal(t, expected, flag)
+		}
+	})
+
+	t.Run("Returns invalid param error when bool is invalid", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/?flag=whatever", nil)
+
+		_, err := BoolQueryParam(request, "flag")
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "INVALID_PARAM", err.GetCode())
+	})
+}
+
+func TestIntQueryParam(t *testing.T) {
+	t.Run("Parses int query param", func(t *testing.T) {
+		testCases := map[string]int{
+			"":   1,
+			"0":  0,
+			"10": 10,
+			"-1": -1,
+		}
+
+		for value, expected := range testCases {
+			request := httptest.NewRequest(http.MethodPost, "/?limit="+value, nil)
+
+			limit, err := IntQueryParam(request, "limit", 1)
+
+			assert.Nil(t, err)
+			assert.Equal(t, expected, limit)
+		}
+	})
+
+	t.Run("Returns invalid param error when int is invalid", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/?limit=whatever", nil)
+
+		_, err := IntQueryParam(request, "limit", 1)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "INVALID_PARAM", err.GetCode())
+	})
+}
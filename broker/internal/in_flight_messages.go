@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package internal
+
+import (
+	"container/heap"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type inFlightMessage struct {
+	message *Message
+	until   time.Time
+	// index in the visibility deadlines heap, -1 when the message never becomes visible again on its own
+	index int
+	// notified of the message id once the message is no longer in flight, nil unless delivered through a subscription
+	settled chan<- uuid.UUID
+}
+
+// settle notifies the subscription the message was delivered through that it is no longer in flight
+func (e *inFlightMessage) settle() {
+	if e.settled == nil {
+		return
+	}
+
+	// never blocks: subscriptions have room for as many notifications as messages they can have in flight
+	select {
+	case e.settled <- e.message.Id:
+	default:
+	}
+}
+
+// earliestInFlight returns up to limit messages being processed, in publishing order, only keeping & sorting the
+// earliest published ones rather than the whole of them
+func earliestInFlight(inFlight map[uuid.UUID]*inFlightMessage, limit int) []*Message {
+	if limit <= 0 {
+		return []*Message{}
+	}
+
+	earliest := make(latestPublished, 0, min(limit, len(inFlight)))
+	for _, entry := range inFlight {
+		if len(earliest) < limit {
+			heap.Push(&earliest, entry.message)
+		} else if entry.message.sequence < earliest[0].sequence {
+			earliest[0] = entry.message
+			heap.Fix(&earliest, 0)
+		}
+	}
+
+	messages := []*Message(earliest)
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].sequence < messages[j].sequence
+	})
+
+	return messages
+}
+
+// latestPublished is a max-heap (container/heap) of messages, the latest published first
+type latestPublished []*Message
+
+func (h latestPublished) Len() int {
+	return len(h)
+}
+
+func (h latestPublished) Less(i, j int) bool {
+	return h[i].sequence > h[j].sequence
+}
+
+func (h latestPublished) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *latestPublished) Push(x any) {
+	*h = append(*h, x.(*Message))
+}
+
+func (h *latestPublished) Pop() any {
+	old := *h
+	n := len(old)
+	message := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+
+	return message
+}
+
+// visibilityDeadlines is a min-heap (container/heap) of the messages being processed, the earliest visibility timeout first
+type visibilityDeadlines []*inFlightMessage
+
+func (h visibilityDeadlines) Len() int {
+	return len(h)
+}
+
+func (h visibilityDeadlines) Less(i, j int) bool {
+	return h[i].until.Before(h[j].until)
+}
+
+func (h visibilityDeadlines) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *visibilityDeadlines) Push(x any) {
+	entry := x.(*inFlightMessage)
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *visibilityDeadlines) Pop() any {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	entry.index = -1
+	*h = old[:n-1]
+
+	return entry
+}
@@ -5,16 +5,163 @@
 package internal
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+
+	"github.com/melyouz/risala/broker/internal/errs"
 )
 
+const DeathReasonHeader = "x-death-reason"
+const DeathQueueHeader = "x-death-queue"
+const DeathExchangeHeader = "x-death-exchange"
+const DeathRoutingKeyHeader = "x-death-routing-key"
+const DeathTimeHeader = "x-death-time"
+const PayloadEncodingBase64 = "base64"
+
 type Message struct {
 	sync.Mutex
-	Id         uuid.UUID `json:"id" validate:"required"`
-	Payload    string    `json:"payload" validate:"required"`
-	Processing bool      `json:"isProcessing"`
+	Id              uuid.UUID         `json:"id" validate:"required"`
+	Payload         string            `json:"payload" validate:"required"`
+	RoutingKey      string            `json:"routingKey"`
+	Exchange        string            `json:"exchange,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	ContentType     string            `json:"contentType,omitempty"`
+	CorrelationId   string            `json:"correlationId,omitempty"`
+	ReplyTo         string            `json:"replyTo,omitempty"`
+	Timestamp       time.Time         `json:"timestamp"`
+	Expiration      int               `json:"expiration,omitempty" validate:"gte=0"`
+	ExpiresAt       *time.Time        `json:"expiresAt,omitempty"`
+	Priority        int               `json:"priority,omitempty" validate:"gte=0,lte=255"`
+	Delay           int               `json:"delay,omitempty" validate:"gte=0"`
+	DeliverAt       *time.Time        `json:"deliverAt,omitempty" validate:"excluded_with=Delay"`
+	Delayed         bool              `json:"isDelayed"`
+	DeduplicationId string            `json:"deduplicationId,omitempty"`
+	Duplicate       bool              `json:"isDuplicate,omitempty"`
+	GroupId         string            `json:"groupId,omitempty"`
+	Processing      bool              `json:"isProcessing"`
+	ProcessingUntil time.Time         `json:"-"`
+	AvailableAt     time.Time         `json:"-"`
+	DeliveryCount   int               `json:"deliveryCount"`
+	// position in its Queue, keeping the publishing order of messages requeued or becoming due
+	sequence uint64
+}
+
+type messageJSON Message
+
+func (m *Message) MarshalJSON() ([]byte, error) {
+	// messages are encoded while sitting in their queue, delivered concurrently
+	m.Lock()
+	defer m.Unlock()
+
+	payload, payloadEncoding := m.Payload, ""
+	if !utf8.ValidString(m.Payload) {
+		payload, payloadEncoding = base64.StdEncoding.EncodeToString([]byte(m.Payload)), PayloadEncodingBase64
+	}
+
+	return json.Marshal(&struct {
+		*messageJSON
+		Payload         string `json:"payload"`
+		PayloadEncoding string `json:"payloadEncoding,omitempty"`
+	}{
+		messageJSON:     (*messageJSON)(m),
+		Payload:         payload,
+		PayloadEncoding: payloadEncoding,
+	})
+}
+
+func (m *Message) UnmarshalJSON(data []byte) error {
+	wire := struct {
+		*messageJSON
+		Payload         string `json:"payload"`
+		PayloadEncoding string `json:"payloadEncoding"`
+	}{
+		messageJSON: (*messageJSON)(m),
+	}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	payload, payloadErr := DecodePayload(wire.Payload, wire.PayloadEncoding)
+	if payloadErr != nil {
+		return payloadErr
+	}
+	m.Payload = payload
+
+	return nil
+}
+
+// DecodePayload decodes a payload serialized to JSON with the given encoding, none meaning the payload is plain text
+func DecodePayload(encoded string, encoding string) (payload string, err errs.AppError) {
+	switch encoding {
+	case "":
+		return encoded, nil
+	case PayloadEncodingBase64:
+		decoded, decodeErr := base64.StdEncoding.DecodeString(encoded)
+		if decodeErr != nil {
+			return "", errs.NewParamInvalidError("payload", "Invalid base64 payload")
+		}
+		return string(decoded), nil
+	default:
+		return "", errs.NewParamInvalidError("payloadEncoding", fmt.Sprintf("Invalid value '%s' for 'payloadEncoding'. Must be one of: %s", encoding, PayloadEncodingBase64))
+	}
+}
+
+func (m *Message) ToDeadLetter(queueName string, reason DeadLetterReason) *Message {
+	m.Lock()
+	defer m.Unlock()
+
+	headers := make(map[string]string, len(m.Headers)+5)
+	for k, v := range m.Headers {
+		headers[k] = v
+	}
+	headers[DeathReasonHeader] = reason.String()
+	headers[DeathQueueHeader] = queueName
+	headers[DeathExchangeHeader] = m.Exchange
+	headers[DeathRoutingKeyHeader] = m.RoutingKey
+	headers[DeathTimeHeader] = time.Now().UTC().Format(time.RFC3339)
+
+	return &Message{
+		Id:            m.Id,
+		Payload:       m.Payload,
+		RoutingKey:    m.RoutingKey,
+		Headers:       headers,
+		ContentType:   m.ContentType,
+		CorrelationId: m.CorrelationId,
+		ReplyTo:       m.ReplyTo,
+		Timestamp:     m.Timestamp,
+		Priority:      m.Priority,
+		GroupId:       m.GroupId,
+	}
+}
+
+// Copy returns a new delivery of the message: same (shared) body, new id & fresh delivery state.
+func (m *Message) Copy() *Message {
+	m.Lock()
+	defer m.Unlock()
+
+	return &Message{
+		Id:              uuid.New(),
+		Payload:         m.Payload,
+		RoutingKey:      m.RoutingKey,
+		Exchange:        m.Exchange,
+		Headers:         m.Headers,
+		ContentType:     m.ContentType,
+		CorrelationId:   m.CorrelationId,
+		ReplyTo:         m.ReplyTo,
+		Timestamp:       m.Timestamp,
+		Expiration:      m.Expiration,
+		Priority:        m.Priority,
+		Delay:           m.Delay,
+		DeliverAt:       m.DeliverAt,
+		DeduplicationId: m.DeduplicationId,
+		GroupId:         m.GroupId,
+	}
 }
 
 func (m *Message) MarkProcessing() {
@@ -24,11 +171,64 @@ func (m *Message) MarkProcessing() {
 	m.Processing = true
 }
 
+func (m *Message) Deliver(now time.Time, visibilityTimeout time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.Processing = true
+	m.ProcessingUntil = now.Add(visibilityTimeout)
+	m.DeliveryCount++
+}
+
+func (m *Message) Requeue(availableAt time.Time) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.Processing = false
+	m.ProcessingUntil = time.Time{}
+	m.AvailableAt = availableAt
+}
+
 func (m *Message) UnmarkProcessing() {
 	m.Lock()
 	defer m.Unlock()
 
 	m.Processing = false
+	m.ProcessingUntil = time.Time{}
+}
+
+func (m *Message) IsExpired(now time.Time) bool {
+	m.Lock()
+	defer m.Unlock()
+
+	return !m.Processing && m.ExpiresAt != nil && !now.Before(*m.ExpiresAt)
+}
+
+// MarkDuplicateOf points the message to the original one already published with the same deduplication id
+func (m *Message) MarkDuplicateOf(originalId uuid.UUID) {
+	m.Id = originalId
+	m.Duplicate = true
+}
+
+func (m *Message) SetDelayed(delayed bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.Delayed = delayed
+}
+
+func (m *Message) GetAvailableAt() time.Time {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.AvailableAt
+}
+
+func (m *Message) GetDeliveryCount() int {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.DeliveryCount
 }
 
 func (m *Message) IsProcessing() bool {
@@ -0,0 +1,107 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package internal
+
+import (
+	"container/list"
+
+	"github.com/google/uuid"
+)
+
+// messageGroups lets a single message per group be ready or in flight at a time (its head), the following messages of
+// the group waiting their turn in publishing order
+type messageGroups struct {
+	heads map[string]uuid.UUID
+	// waiting messages of each group, as elements of queued
+	waiting map[string]*list.List
+	// waiting messages of all the groups, in the order they were queued
+	queued list.List
+	length int
+	bytes  int
+}
+
+// admit makes the message the head of its group if the group has none, otherwise it waits behind the messages of the
+// group: returns whether the message can be delivered
+func (g *messageGroups) admit(message *Message) bool {
+	if message.GroupId == "" {
+		return true
+	}
+
+	head, ok := g.heads[message.GroupId]
+	if !ok {
+		g.claim(message)
+		return true
+	}
+	if head == message.Id {
+		return true
+	}
+
+	if g.waiting == nil {
+		g.waiting = make(map[string]*list.List)
+	}
+	waiting, ok := g.waiting[message.GroupId]
+	if !ok {
+		waiting = list.New()
+		g.waiting[message.GroupId] = waiting
+	}
+	waiting.PushBack(g.queued.PushBack(message))
+	g.length++
+	g.bytes += len(message.Payload)
+
+	return false
+}
+
+// claim makes the message the head of its group
+func (g *messageGroups) claim(message *Message) {
+	if message.GroupId == "" {
+		return
+	}
+
+	if g.heads == nil {
+		g.heads = make(map[string]uuid.UUID)
+	}
+	g.heads[message.GroupId] = message.Id
+}
+
+// release frees the group of a message leaving the queue, returning the next message of the group, now its head
+func (g *messageGroups) release(message *Message) (next *Message) {
+	if message.GroupId == "" || g.heads[message.GroupId] != message.Id {
+		return nil
+	}
+
+	delete(g.heads, message.GroupId)
+	waiting, ok := g.waiting[message.GroupId]
+	if !ok {
+		return nil
+	}
+
+	next = g.queued.Remove(waiting.Remove(waiting.Front()).(*list.Element)).(*Message)
+	if waiting.Len() == 0 {
+		delete(g.waiting, message.GroupId)
+	}
+	g.length--
+	g.bytes -= len(next.Payload)
+	g.claim(next)
+
+	return next
+}
+
+// list returns up to limit waiting messages, in the order they were queued
+func (g *messageGroups) list(limit int) (messages []*Message) {
+	messages = make([]*Message, 0, min(limit, g.length))
+	for e := g.queued.Front(); e != nil && len(messages) < limit; e = e.Next() {
+		messages = append(messages, e.Value.(*Message))
+	}
+
+	return messages
+}
+
+func (g *messageGroups) clear() {
+	g.heads = nil
+	g.waiting = nil
+	g.queued.Init()
+	g.length = 0
+	g.bytes = 0
+}
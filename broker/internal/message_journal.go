@@ -0,0 +1,22 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package internal
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/melyouz/risala/broker/internal/errs"
+)
+
+type MessageJournal interface {
+	Append(message *Message) (err errs.AppError)
+	Remove(messageId uuid.UUID) (err errs.AppError)
+	// Update records the delivery state of a message, surviving a restart: its delivery count & the time it becomes
+	// visible again (zero when visible right away)
+	Update(messageId uuid.UUID, deliveryCount int, availableAt time.Time) (err errs.AppError)
+	Truncate() (err errs.AppError)
+}
@@ -0,0 +1,21 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package internal
+
+type OverflowPolicy string
+
+var OverflowPolicies = struct {
+	DROP_HEAD        OverflowPolicy
+	REJECT_PUBLISH   OverflowPolicy
+	DEAD_LETTER_HEAD OverflowPolicy
+}{
+	DROP_HEAD:        "drop-head",
+	REJECT_PUBLISH:   "reject-publish",
+	DEAD_LETTER_HEAD: "dead-letter-head",
+}
+
+func (p *OverflowPolicy) String() string {
+	return string(*p)
+}
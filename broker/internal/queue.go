@@ -5,9 +5,14 @@
 package internal
 
 import (
+	"cmp"
+	"container/heap"
+	"context"
 	"fmt"
 	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -15,97 +20,742 @@ import (
 )
 
 const DeadLetterQueueName = "system.dead-letter"
+const DefaultVisibilityTimeout = 30 * time.Second
+const MaxVisibilityTimeout = 12 * time.Hour
+const MaxDequeueWait = 20 * time.Second
+const MaxBatchSize = 100
 
 type Queue struct {
 	sync.RWMutex
-	Name       string         `json:"name" validate:"required"`
-	Durability DurabilityType `json:"durability" validate:"required,oneof=durable transient"`
-	Messages   []*Message     `json:"-" validate:"dive"`
-	System     bool           `json:"isSystem"`
+	Name                 string         `json:"name" validate:"required"`
+	Durability           DurabilityType `json:"durability" validate:"required,oneof=durable transient"`
+	VisibilityTimeout    int            `json:"visibilityTimeout" validate:"gte=0,lte=43200"`
+	MaxDeliveries        int            `json:"maxDeliveries" validate:"gte=0"`
+	DeadLetterExchange   string         `json:"deadLetterExchange,omitempty"`
+	DeadLetterRoutingKey string         `json:"deadLetterRoutingKey,omitempty"`
+	MessageTtl           int            `json:"messageTtl" validate:"gte=0"`
+	DeadLetterExpired    bool           `json:"deadLetterExpired"`
+	MaxLength            int            `json:"maxLength" validate:"gte=0"`
+	MaxBytes             int            `json:"maxBytes" validate:"gte=0"`
+	Overflow             OverflowPolicy `json:"overflow,omitempty" validate:"omitempty,oneof=drop-head reject-publish dead-letter-head"`
+	MaxPriority          int            `json:"maxPriority" validate:"gte=0,lte=255"`
+	DeduplicationWindow  int            `json:"deduplicationWindow" validate:"gte=0"`
+	System               bool           `json:"isSystem"`
+	journal              MessageJournal
+	changed              chan struct{}
+	deadLetters          []PendingDeadLetter
+	ready                readyMessages
+	inFlight             map[uuid.UUID]*inFlightMessage
+	visibilityDeadlines  visibilityDeadlines
+	delayed              delayedMessages
+	delayedBytes         int
+	sequence             uint64
+	deduplication        deduplicationCache
+	groups               messageGroups
 }
 
 func (q *Queue) Enqueue(message *Message) (err errs.AppError) {
-	q.Lock()
-	defer q.Unlock()
+	_, err = EnqueueAll([]*Queue{q}, message)
 
-	q.Messages = append(q.Messages, message)
+	return err
+}
 
-	return nil
+// EnqueueAll enqueues the message into all the queues at once, returning the ones that didn't drop it as a duplicate:
+// the message is only marked as a duplicate when all of them did
+func EnqueueAll(queues []*Queue, message *Message) (enqueued []*Queue, err errs.AppError) {
+	message.Duplicate = false
+	queues = slices.Clone(queues)
+	slices.SortFunc(queues, func(a, b *Queue) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	queues = slices.CompactFunc(queues, func(a, b *Queue) bool {
+		return a.Name == b.Name
+	})
+
+	// each queue gets its own delivery so that consumers on different queues don't share delivery state
+	deliveries := make([]*Message, len(queues))
+	for i := range queues {
+		deliveries[i] = message
+		if len(queues) > 1 {
+			deliveries[i] = message.Copy()
+		}
+	}
+
+	// lock in name order so that concurrent publishes to overlapping queues can't deadlock
+	for _, q := range queues {
+		q.Lock()
+		defer q.Unlock()
+	}
+
+	now := time.Now()
+
+	// duplicates are only dropped from the queues that got the message within their deduplication window, queues
+	// remembering the id returned on publish rather than the one of their own delivery
+	kept := 0
+	originalIds := make([]uuid.UUID, 0)
+	for i, q := range queues {
+		if message.DeduplicationId != "" && q.DeduplicationWindow > 0 {
+			window := time.Duration(q.DeduplicationWindow) * time.Second
+			originalId, duplicate := q.deduplication.remember(message.DeduplicationId, message.Id, now, window)
+			if duplicate {
+				originalIds = append(originalIds, originalId)
+				continue
+			}
+		}
+
+		queues[kept], deliveries[kept] = q, deliveries[i]
+		kept++
+	}
+	queues, deliveries = queues[:kept], deliveries[:kept]
+	if len(queues) == 0 && len(originalIds) > 0 {
+		message.MarkDuplicateOf(originalIds[0])
+		return queues, nil
+	}
+	defer func() {
+		if err != nil {
+			for _, q := range queues {
+				q.deduplication.forget(message.DeduplicationId, message.Id)
+			}
+		}
+	}()
+
+	due := now
+	if message.Delay > 0 {
+		deliverAt := now.Add(time.Duration(message.Delay) * time.Second)
+		message.DeliverAt = &deliverAt
+	}
+	if message.DeliverAt != nil && message.DeliverAt.After(now) {
+		due = *message.DeliverAt
+	}
+
+	for i, q := range queues {
+		deliveries[i].DeliverAt = message.DeliverAt
+		deliveries[i].Delayed = due.After(now)
+		if deliveries[i].Delayed {
+			deliveries[i].AvailableAt = due
+		}
+		// the time to live only starts once the message is due
+		deliveries[i].ExpiresAt = q.expiresAt(due, deliveries[i].Expiration)
+		if q.Overflow == OverflowPolicies.REJECT_PUBLISH && q.wouldOverflow(deliveries[i]) {
+			return nil, errs.NewQueueFullError(fmt.Sprintf("Queue '%s' is full", q.Name))
+		}
+	}
+
+	for i, q := range queues {
+		if q.journal == nil {
+			continue
+		}
+
+		journalErr := q.journal.Append(deliveries[i])
+		if journalErr != nil {
+			for j, appended := range queues[:i] {
+				_ = appended.removeFromJournal(deliveries[j].Id)
+			}
+
+			return nil, journalErr
+		}
+	}
+
+	for i, q := range queues {
+		q.sequence++
+		deliveries[i].sequence = q.sequence
+		if deliveries[i].Delayed {
+			q.delay(deliveries[i])
+		} else {
+			q.admit(deliveries[i])
+			q.dropOverflow()
+		}
+		// waiters include the delayed messages in the deadline they wait for
+		q.notifyChanged()
+	}
+
+	return queues, nil
 }
 
 func (q *Queue) Dequeue() (message *Message) {
+	return q.DequeueWithVisibilityTimeout(0)
+}
+
+func (q *Queue) DequeueWithVisibilityTimeout(visibilityTimeout time.Duration) (message *Message) {
+	messages := q.DequeueBatch(1, visibilityTimeout)
+	if len(messages) == 0 {
+		return nil
+	}
+
+	return messages[0]
+}
+
+func (q *Queue) DequeueBatch(limit int, visibilityTimeout time.Duration) (messages []*Message) {
+	return q.dequeueBatch(limit, visibilityTimeout, nil)
+}
+
+// dequeueBatch delivers up to limit messages, notifying settled once each of them is no longer in flight
+func (q *Queue) dequeueBatch(limit int, visibilityTimeout time.Duration, settled chan<- uuid.UUID) (messages []*Message) {
 	q.Lock()
 	defer q.Unlock()
 
-	messagesCount := len(q.Messages)
-	if messagesCount == 0 {
+	messages = make([]*Message, 0)
+	if visibilityTimeout <= 0 {
+		visibilityTimeout = q.GetVisibilityTimeout()
+	}
+
+	now := time.Now()
+	q.reclaimExpired(now)
+	q.promoteDue(now)
+	for len(messages) < limit {
+		m := q.nextReady(now)
+		if m == nil {
+			break
+		}
+		if q.updateInJournal(m.Id, m.GetDeliveryCount()+1, time.Time{}) != nil {
+			q.ready.pushFront(m, q.priority(m))
+			break
+		}
+
+		m.Deliver(now, visibilityTimeout)
+		q.track(m, settled)
+		messages = append(messages, m)
+	}
+
+	return messages
+}
+
+func (q *Queue) DequeueWithWait(ctx context.Context, visibilityTimeout time.Duration, wait time.Duration) (message *Message) {
+	messages := q.DequeueBatchWithWait(ctx, 1, visibilityTimeout, wait)
+	if len(messages) == 0 {
 		return nil
 	}
 
-	for _, m := range q.Messages {
-		if !m.IsProcessing() {
-			m.MarkProcessing()
-			return m
+	return messages[0]
+}
+
+func (q *Queue) DequeueBatchWithWait(ctx context.Context, limit int, visibilityTimeout time.Duration, wait time.Duration) (messages []*Message) {
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+
+	for {
+		changed := q.Changed()
+		messages = q.DequeueBatch(limit, visibilityTimeout)
+		if len(messages) > 0 || wait <= 0 {
+			return messages
+		}
+
+		due, stopDue := q.untilDue()
+		select {
+		case <-ctx.Done():
+			stopDue()
+			return messages
+		case <-timeout.C:
+			stopDue()
+			return q.DequeueBatch(limit, visibilityTimeout)
+		case <-changed:
+		case <-due:
 		}
+		stopDue()
 	}
+}
+
+func (q *Queue) Ack(messageId uuid.UUID) (err errs.AppError) {
+	q.Lock()
+	defer q.Unlock()
+
+	q.reclaimExpired(time.Now())
+
+	entry, ok := q.inFlight[messageId]
+	if !ok {
+		return errs.NewMessageNotFoundError(fmt.Sprintf("Message '%s' not found", messageId.String()))
+	}
+
+	journalErr := q.removeFromJournal(messageId)
+	if journalErr != nil {
+		return journalErr
+	}
+
+	q.untrack(messageId)
+	q.release(entry.message)
+	q.notifyChanged()
 
 	return nil
 }
 
-func (q *Queue) Ack(messageId uuid.UUID) (err errs.AppError) {
+func (q *Queue) Nack(messageId uuid.UUID, requeue bool, delay time.Duration) (message *Message, err errs.AppError) {
 	q.Lock()
 	defer q.Unlock()
 
-	for i, m := range q.Messages {
-		if m.Id == messageId && m.IsProcessing() {
-			q.Messages = slices.Delete(q.Messages, i, i+1)
-			return nil
+	q.reclaimExpired(time.Now())
+
+	entry, ok := q.inFlight[messageId]
+	if !ok {
+		return nil, errs.NewMessageNotFoundError(fmt.Sprintf("Message '%s' not found", messageId.String()))
+	}
+
+	m := entry.message
+	if requeue && !q.hasExceededMaxDeliveries(m) {
+		var availableAt time.Time
+		if delay > 0 {
+			availableAt = time.Now().Add(delay)
+		}
+		journalErr := q.updateInJournal(messageId, m.GetDeliveryCount(), availableAt)
+		if journalErr != nil {
+			return nil, journalErr
+		}
+
+		q.untrack(messageId)
+		m.Requeue(availableAt)
+		if delay > 0 {
+			m.SetDelayed(true)
+			q.delay(m)
+		} else {
+			q.ready.pushFront(m, q.priority(m))
 		}
+		q.notifyChanged()
+		return nil, nil
 	}
 
-	return errs.NewMessageNotFoundError(fmt.Sprintf("Message '%s' not found", messageId.String()))
+	journalErr := q.removeFromJournal(messageId)
+	if journalErr != nil {
+		return nil, journalErr
+	}
+
+	q.untrack(messageId)
+	q.release(m)
+	m.UnmarkProcessing()
+	q.notifyChanged()
+
+	return m, nil
+}
+
+// Release makes a message being processed visible again right away, without it being acknowledged nor dead-lettered
+func (q *Queue) Release(messageId uuid.UUID) {
+	q.Lock()
+	defer q.Unlock()
+
+	entry, ok := q.inFlight[messageId]
+	if !ok {
+		return
+	}
+
+	q.untrack(messageId)
+	entry.message.UnmarkProcessing()
+	q.ready.pushFront(entry.message, q.priority(entry.message))
+	q.notifyChanged()
 }
 
-func (q *Queue) Nack(messageId uuid.UUID) (message *Message, err errs.AppError) {
+// releaseSubscribed makes the messages delivered through the subscription notified on settled visible again right away, leaving out the ones
+// no longer in flight for it (e.g. redelivered to another consumer once their visibility timeout was over)
+func (q *Queue) releaseSubscribed(messageIds []uuid.UUID, settled chan<- uuid.UUID) {
 	q.Lock()
 	defer q.Unlock()
 
-	for i, m := range q.Messages {
-		if m.Id == messageId && m.IsProcessing() {
-			m.UnmarkProcessing()
-			q.Messages = slices.Delete(q.Messages, i, i+1)
-			return m, nil
+	released := make([]*Message, 0, len(messageIds))
+	for _, messageId := range messageIds {
+		entry, ok := q.inFlight[messageId]
+		if !ok || entry.settled != settled {
+			continue
 		}
+
+		q.untrack(messageId)
+		entry.message.UnmarkProcessing()
+		released = append(released, entry.message)
 	}
 
-	return nil, errs.NewMessageNotFoundError(fmt.Sprintf("Message '%s' not found", messageId.String()))
+	// pushed to the front latest first, so that released messages keep their publishing order
+	slices.SortFunc(released, func(a, b *Message) int {
+		return cmp.Compare(b.sequence, a.sequence)
+	})
+	for _, m := range released {
+		q.ready.pushFront(m, q.priority(m))
+	}
+	if len(released) > 0 {
+		q.notifyChanged()
+	}
 }
 
+// Peek lists the messages being processed, then the ones ready to be delivered, the ones waiting for their group &
+// finally the delayed ones
 func (q *Queue) Peek(limit int) (messages []*Message, err errs.AppError) {
 	q.Lock()
 	defer q.Unlock()
 
-	messagesCount := len(q.Messages)
-	if messagesCount == 0 || limit <= 0 {
+	now := time.Now()
+	q.reclaimExpired(now)
+	q.promoteDue(now)
+	q.removeExpired(now)
+
+	if limit <= 0 {
 		return make([]*Message, 0), nil
 	}
 
-	if limit > messagesCount {
-		limit = messagesCount
-	}
+	return q.list(limit), nil
+}
+
+// GetMessages lists all the messages of the queue, in the same order as Peek
+func (q *Queue) GetMessages() (messages []*Message) {
+	q.Lock()
+	defer q.Unlock()
 
-	return q.Messages[:limit], nil
+	return q.list(len(q.inFlight) + q.ready.length + q.groups.length + len(q.delayed))
 }
 
 func (q *Queue) Purge() (err errs.AppError) {
 	q.Lock()
 	defer q.Unlock()
 
-	q.Messages = []*Message{}
+	if q.journal != nil {
+		journalErr := q.journal.Truncate()
+		if journalErr != nil {
+			return journalErr
+		}
+	}
+
+	for _, entry := range q.inFlight {
+		entry.settle()
+	}
+	q.ready.clear()
+	q.groups.clear()
+	q.inFlight = nil
+	q.visibilityDeadlines = nil
+	q.delayed = nil
+	q.delayedBytes = 0
+	q.notifyChanged()
 
 	return nil
 }
 
+func (q *Queue) TakeDeadLetters() (deadLetters []PendingDeadLetter) {
+	q.Lock()
+	defer q.Unlock()
+
+	now := time.Now()
+	q.reclaimExpired(now)
+	q.promoteDue(now)
+	q.removeExpired(now)
+
+	deadLetters, q.deadLetters = q.deadLetters, nil
+
+	return deadLetters
+}
+
+// ReturnDeadLetters hands back messages that failed to be dead-lettered, to be taken again on the next sweep
+func (q *Queue) ReturnDeadLetters(deadLetters []PendingDeadLetter) {
+	if len(deadLetters) == 0 {
+		return
+	}
+
+	q.Lock()
+	defer q.Unlock()
+
+	q.deadLetters = append(deadLetters, q.deadLetters...)
+}
+
+func (q *Queue) Changed() <-chan struct{} {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.changed == nil {
+		q.changed = make(chan struct{})
+	}
+
+	return q.changed
+}
+
+// untilDue returns a channel receiving once messages become available without the queue changing (delayed messages
+// coming due, visibility timeouts ending), nil if none, along with the function releasing it
+func (q *Queue) untilDue() (due <-chan time.Time, stop func() bool) {
+	q.Lock()
+	// messages whose visibility timeout is over settle right away, rather than whenever the queue is next used
+	q.reclaimExpired(time.Now())
+	var next time.Time
+	if len(q.delayed) > 0 {
+		next = q.delayed[0].AvailableAt
+	}
+	if len(q.visibilityDeadlines) > 0 && (next.IsZero() || q.visibilityDeadlines[0].until.Before(next)) {
+		next = q.visibilityDeadlines[0].until
+	}
+	q.Unlock()
+
+	if next.IsZero() {
+		return nil, func() bool { return false }
+	}
+
+	// visibility timeouts end strictly after their deadline
+	timer := time.NewTimer(time.Until(next) + time.Millisecond)
+
+	return timer.C, timer.Stop
+}
+
 func (q *Queue) IsSystem() bool {
 	return q.System
 }
+
+func (q *Queue) GetVisibilityTimeout() time.Duration {
+	if q.VisibilityTimeout <= 0 {
+		return DefaultVisibilityTimeout
+	}
+
+	return time.Duration(q.VisibilityTimeout) * time.Second
+}
+
+func (q *Queue) IsDurable() bool {
+	return q.Durability == Durability.DURABLE
+}
+
+func (q *Queue) AttachJournal(journal MessageJournal) {
+	q.Lock()
+	defer q.Unlock()
+
+	q.journal = journal
+}
+
+// RestoreMessages replaces the messages of the queue, each one according to its state (being processed, delayed or ready)
+func (q *Queue) RestoreMessages(messages []*Message) {
+	q.Lock()
+	defer q.Unlock()
+
+	q.ready.clear()
+	q.groups.clear()
+	q.inFlight = nil
+	q.visibilityDeadlines = nil
+	q.delayed = nil
+	q.delayedBytes = 0
+
+	// messages being processed are the heads of their groups, whatever their position
+	for _, m := range messages {
+		if m.IsProcessing() {
+			q.groups.claim(m)
+		}
+	}
+
+	now := time.Now()
+	for _, m := range messages {
+		q.sequence++
+		m.sequence = q.sequence
+		// messages requeued with a delay become visible again at the latest of their delivery time & requeue delay
+		if m.DeliverAt != nil && m.DeliverAt.After(m.AvailableAt) {
+			m.AvailableAt = *m.DeliverAt
+		}
+		switch {
+		case m.IsProcessing():
+			q.track(m, nil)
+		case m.AvailableAt.After(now):
+			m.Delayed = true
+			q.delay(m)
+		default:
+			m.Delayed = false
+			q.admit(m)
+		}
+	}
+}
+
+func (q *Queue) list(limit int) (messages []*Message) {
+	messages = make([]*Message, 0, min(limit, len(q.inFlight)+q.ready.length+q.groups.length+len(q.delayed)))
+
+	messages = append(messages, earliestInFlight(q.inFlight, limit)...)
+	messages = append(messages, q.ready.list(limit-len(messages))...)
+	messages = append(messages, q.groups.list(limit-len(messages))...)
+	// delayed messages come last, the earliest due first
+	messages = append(messages, q.delayed.earliest(limit-len(messages))...)
+
+	return messages
+}
+
+func (q *Queue) priority(message *Message) int {
+	return min(message.Priority, q.MaxPriority)
+}
+
+// nextReady removes & returns the next message to be delivered, dropping the expired ones met on the way
+func (q *Queue) nextReady(now time.Time) *Message {
+	for priority := len(q.ready.levels) - 1; priority >= 0; priority-- {
+		q.removeExpiredAt(priority, now)
+		if q.ready.frontAt(priority) != nil {
+			return q.ready.removeFrontAt(priority)
+		}
+	}
+
+	return nil
+}
+
+func (q *Queue) track(message *Message, settled chan<- uuid.UUID) {
+	if q.inFlight == nil {
+		q.inFlight = make(map[uuid.UUID]*inFlightMessage)
+	}
+
+	entry := &inFlightMessage{message: message, until: message.ProcessingUntil, index: -1, settled: settled}
+	q.inFlight[message.Id] = entry
+	if !entry.until.IsZero() {
+		heap.Push(&q.visibilityDeadlines, entry)
+	}
+}
+
+func (q *Queue) untrack(messageId uuid.UUID) {
+	entry := q.inFlight[messageId]
+	delete(q.inFlight, messageId)
+	if entry.index >= 0 {
+		heap.Remove(&q.visibilityDeadlines, entry.index)
+	}
+	entry.settle()
+}
+
+func (q *Queue) expiresAt(now time.Time, expiration int) *time.Time {
+	ttl := q.MessageTtl
+	if expiration > 0 && (ttl <= 0 || expiration < ttl) {
+		ttl = expiration
+	}
+	if ttl <= 0 {
+		return nil
+	}
+
+	expiresAt := now.Add(time.Duration(ttl) * time.Second)
+
+	return &expiresAt
+}
+
+func (q *Queue) delay(message *Message) {
+	heap.Push(&q.delayed, message)
+	q.delayedBytes += len(message.Payload)
+}
+
+// promoteDue moves the delayed messages that are due to the messages ready to be delivered
+func (q *Queue) promoteDue(now time.Time) {
+	promoted := false
+	for len(q.delayed) > 0 && !now.Before(q.delayed[0].AvailableAt) {
+		m := heap.Pop(&q.delayed).(*Message)
+		q.delayedBytes -= len(m.Payload)
+		m.SetDelayed(false)
+		q.admit(m)
+		promoted = true
+	}
+
+	if promoted {
+		q.dropOverflow()
+	}
+}
+
+// removeExpired drops the expired messages waiting to be processed, keeping them aside to be dead-lettered if required
+func (q *Queue) removeExpired(now time.Time) {
+	for priority := range q.ready.levels {
+		q.removeExpiredAt(priority, now)
+	}
+}
+
+// removeExpiredAt drops the expired messages at the head of the given priority: as with per-queue TTLs messages expire
+// in publishing order, a message expiring before the ones ahead of it is only dropped once it reaches the head
+func (q *Queue) removeExpiredAt(priority int, now time.Time) {
+	for {
+		m := q.ready.frontAt(priority)
+		// ExpiresAt is only written while holding the queue lock: cheap check before locking the message
+		if m == nil || m.ExpiresAt == nil || now.Before(*m.ExpiresAt) || !m.IsExpired(now) {
+			return
+		}
+		if q.removeFromJournal(m.Id) != nil {
+			return
+		}
+
+		q.ready.removeFrontAt(priority)
+		q.release(m)
+		if q.DeadLetterExpired {
+			q.deadLetters = append(q.deadLetters, PendingDeadLetter{Message: m, Reason: DeadLetterReasons.EXPIRED})
+		}
+	}
+}
+
+func (q *Queue) isOverLimit(length int, bytes int) bool {
+	return (q.MaxLength > 0 && length > q.MaxLength) || (q.MaxBytes > 0 && bytes > q.MaxBytes)
+}
+
+// wouldOverflow tells whether publishing the message would exceed the limits, delayed messages included so that they
+// can't overflow the queue once due
+func (q *Queue) wouldOverflow(message *Message) bool {
+	length := q.ready.length + q.groups.length + len(q.delayed) + 1
+	bytes := q.ready.bytes + q.groups.bytes + q.delayedBytes + len(message.Payload)
+
+	return q.isOverLimit(length, bytes)
+}
+
+// dropOverflow drops the oldest messages waiting to be processed until the queue is back within its limits, unless
+// publishes are rejected instead
+func (q *Queue) dropOverflow() {
+	if q.Overflow == OverflowPolicies.REJECT_PUBLISH {
+		return
+	}
+
+	for q.isOverLimit(q.ready.length+q.groups.length, q.ready.bytes+q.groups.bytes) {
+		m, priority := q.ready.front()
+		if m == nil || q.removeFromJournal(m.Id) != nil {
+			return
+		}
+
+		q.ready.removeFrontAt(priority)
+		q.release(m)
+		if q.Overflow == OverflowPolicies.DEAD_LETTER_HEAD {
+			q.deadLetters = append(q.deadLetters, PendingDeadLetter{Message: m, Reason: DeadLetterReasons.REJECTED_OVER_LIMIT})
+		}
+	}
+}
+
+// admit makes the message ready to be delivered, unless another message of its group is ahead of it
+func (q *Queue) admit(message *Message) {
+	if q.groups.admit(message) {
+		q.ready.pushBack(message, q.priority(message))
+	}
+}
+
+// release lets the next message of the group of a message leaving the queue be delivered
+func (q *Queue) release(message *Message) {
+	if next := q.groups.release(message); next != nil {
+		q.ready.pushBack(next, q.priority(next))
+	}
+}
+
+func (q *Queue) hasExceededMaxDeliveries(message *Message) bool {
+	return q.MaxDeliveries > 0 && message.DeliveryCount >= q.MaxDeliveries
+}
+
+// reclaimExpired makes the messages whose visibility timeout is over visible again, ahead of the other messages, unless
+// they reached the max deliveries in which case they are kept aside to be dead-lettered
+func (q *Queue) reclaimExpired(now time.Time) {
+	var reclaimed []*Message
+	for len(q.visibilityDeadlines) > 0 && now.After(q.visibilityDeadlines[0].until) {
+		entry := heap.Pop(&q.visibilityDeadlines).(*inFlightMessage)
+		delete(q.inFlight, entry.message.Id)
+		entry.settle()
+		entry.message.UnmarkProcessing()
+		if q.hasExceededMaxDeliveries(entry.message) && q.removeFromJournal(entry.message.Id) == nil {
+			q.release(entry.message)
+			q.deadLetters = append(q.deadLetters, PendingDeadLetter{Message: entry.message, Reason: DeadLetterReasons.MAX_DELIVERIES})
+			continue
+		}
+		reclaimed = append(reclaimed, entry.message)
+	}
+
+	// pushed to the front latest first, so that reclaimed messages keep their publishing order
+	slices.SortFunc(reclaimed, func(a, b *Message) int {
+		return cmp.Compare(b.sequence, a.sequence)
+	})
+	for _, m := range reclaimed {
+		q.ready.pushFront(m, q.priority(m))
+	}
+}
+
+func (q *Queue) notifyChanged() {
+	if q.changed != nil {
+		close(q.changed)
+		q.changed = nil
+	}
+}
+
+func (q *Queue) removeFromJournal(messageId uuid.UUID) (err errs.AppError) {
+	if q.journal == nil {
+		return nil
+	}
+
+	return q.journal.Remove(messageId)
+}
+
+func (q *Queue) updateInJournal(messageId uuid.UUID, deliveryCount int, availableAt time.Time) (err errs.AppError) {
+	if q.journal == nil {
+		return nil
+	}
+
+	return q.journal.Update(messageId, deliveryCount, availableAt)
+}
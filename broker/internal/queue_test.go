@@ -5,13 +5,17 @@
 package internal
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/melyouz/risala/broker/internal/errs"
 )
 
 func TestQueueConcurrency(t *testing.T) {
@@ -53,8 +57,8 @@ func TestQueueConcurrency(t *testing.T) {
 
 		wg.Wait()
 
-		assert.Len(t, q.Messages, numOperations)
-		for _, m := range q.Messages {
+		assert.Len(t, q.GetMessages(), numOperations)
+		for _, m := range q.GetMessages() {
 			assert.True(t, m.IsProcessing(), "All messages should be marked as processing")
 		}
 	})
@@ -99,6 +103,915 @@ func TestQueueConcurrency(t *testing.T) {
 
 		wg.Wait()
 
-		assert.Empty(t, q.Messages)
+		assert.Empty(t, q.GetMessages())
+	})
+
+	t.Run("Messages are redelivered when their visibility timeout expires", func(t *testing.T) {
+		t.Parallel()
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		enqueueErr := q.Enqueue(&Message{Id: uuid.New(), Payload: "Message 1"})
+		assert.Nil(t, enqueueErr)
+
+		message := q.DequeueWithVisibilityTimeout(10 * time.Millisecond)
+		assert.NotNil(t, message)
+		assert.Equal(t, 1, message.DeliveryCount)
+		assert.Nil(t, q.Dequeue())
+
+		time.Sleep(20 * time.Millisecond)
+
+		redelivered := q.Dequeue()
+		assert.NotNil(t, redelivered)
+		assert.Equal(t, message.Id, redelivered.Id)
+		assert.Equal(t, 2, redelivered.DeliveryCount)
+		assert.True(t, redelivered.IsProcessing())
+	})
+
+	t.Run("Messages cannot be acknowledged once their visibility timeout expired", func(t *testing.T) {
+		t.Parallel()
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		enqueueErr := q.Enqueue(&Message{Id: uuid.New(), Payload: "Message 1"})
+		assert.Nil(t, enqueueErr)
+
+		message := q.DequeueWithVisibilityTimeout(10 * time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+
+		ackErr := q.Ack(message.Id)
+		assert.NotNil(t, ackErr)
+		assert.Len(t, q.GetMessages(), 1)
+		assert.False(t, message.IsProcessing())
+	})
+
+	t.Run("Waiting consumers are woken up by enqueued messages", func(t *testing.T) {
+		t.Parallel()
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		var wg sync.WaitGroup
+		numConsumers := 10
+
+		wg.Add(numConsumers)
+		for i := 0; i < numConsumers; i++ {
+			go func() {
+				defer wg.Done()
+				message := q.DequeueWithWait(context.Background(), 0, 5*time.Second)
+				assert.NotNil(t, message)
+			}()
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		for i := 0; i < numConsumers; i++ {
+			enqueueErr := q.Enqueue(&Message{Id: uuid.New(), Payload: fmt.Sprintf("Message %d", i)})
+			assert.Nil(t, enqueueErr)
+		}
+
+		wg.Wait()
+
+		for _, m := range q.GetMessages() {
+			assert.True(t, m.IsProcessing())
+		}
+	})
+
+	t.Run("Waiting consumers are woken up by delayed messages coming due", func(t *testing.T) {
+		t.Parallel()
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		deliverAt := time.Now().Add(50 * time.Millisecond)
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Delayed", DeliverAt: &deliverAt})
+
+		start := time.Now()
+		message := q.DequeueWithWait(context.Background(), 0, 5*time.Second)
+
+		assert.NotNil(t, message)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("Waiting consumers are woken up by delayed messages published while they wait", func(t *testing.T) {
+		t.Parallel()
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			deliverAt := time.Now().Add(50 * time.Millisecond)
+			_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Delayed", DeliverAt: &deliverAt})
+		}()
+
+		start := time.Now()
+		message := q.DequeueWithWait(context.Background(), 0, 5*time.Second)
+
+		assert.NotNil(t, message)
+		assert.Equal(t, "Delayed", message.Payload)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("Waiting consumers are woken up by visibility timeouts ending", func(t *testing.T) {
+		t.Parallel()
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Hello"})
+		_ = q.DequeueWithVisibilityTimeout(50 * time.Millisecond)
+
+		start := time.Now()
+		message := q.DequeueWithWait(context.Background(), 0, 5*time.Second)
+
+		assert.NotNil(t, message)
+		assert.Equal(t, 2, message.DeliveryCount)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("Waiting consumers give up once their context is done", func(t *testing.T) {
+		t.Parallel()
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		message := q.DequeueWithWait(ctx, 0, 5*time.Second)
+
+		assert.Nil(t, message)
+	})
+}
+
+type failingJournal struct {
+	appended []uuid.UUID
+	fail     bool
+}
+
+func (j *failingJournal) Append(message *Message) (err errs.AppError) {
+	if j.fail {
+		return errs.NewStorageError("Error appending message")
+	}
+	j.appended = append(j.appended, message.Id)
+
+	return nil
+}
+
+func (j *failingJournal) Remove(messageId uuid.UUID) (err errs.AppError) {
+	j.appended = slices.DeleteFunc(j.appended, func(id uuid.UUID) bool { return id == messageId })
+
+	return nil
+}
+
+func (j *failingJournal) Update(messageId uuid.UUID, deliveryCount int, availableAt time.Time) (err errs.AppError) {
+	return nil
+}
+
+func (j *failingJournal) Truncate() (err errs.AppError) {
+	j.appended = nil
+
+	return nil
+}
+
+func TestEnqueueAll(t *testing.T) {
+	t.Run("Enqueues message into every queue once", func(t *testing.T) {
+		orders := &Queue{Name: "orders", Durability: Durability.DURABLE}
+		products := &Queue{Name: "products", Durability: Durability.DURABLE}
+		message := &Message{Id: uuid.New(), Payload: "Hello"}
+
+		_, err := EnqueueAll([]*Queue{products, orders, products}, message)
+
+		assert.Nil(t, err)
+		assert.Len(t, orders.GetMessages(), 1)
+		assert.Len(t, products.GetMessages(), 1)
+		assert.NotEqual(t, orders.GetMessages()[0].Id, products.GetMessages()[0].Id)
+	})
+
+	t.Run("Returns the queues not dropping message as a duplicate", func(t *testing.T) {
+		orders := &Queue{Name: "orders", Durability: Durability.DURABLE, DeduplicationWindow: 60}
+		products := &Queue{Name: "products", Durability: Durability.DURABLE}
+		original := &Message{Id: uuid.New(), Payload: "Hello", DeduplicationId: "hello-1"}
+		_, _ = EnqueueAll([]*Queue{orders, products}, original)
+		message := &Message{Id: uuid.New(), Payload: "Hello", DeduplicationId: "hello-1"}
+
+		enqueued, err := EnqueueAll([]*Queue{orders, products}, message)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []*Queue{products}, enqueued)
+		assert.False(t, message.Duplicate)
+		assert.Len(t, orders.GetMessages(), 1)
+		assert.Len(t, products.GetMessages(), 2)
+	})
+
+	t.Run("Marks message as a duplicate of the original one when every queue drops it", func(t *testing.T) {
+		orders := &Queue{Name: "orders", Durability: Durability.DURABLE, DeduplicationWindow: 60}
+		products := &Queue{Name: "products", Durability: Durability.DURABLE, DeduplicationWindow: 60}
+		original := &Message{Id: uuid.New(), Payload: "Hello", DeduplicationId: "hello-1"}
+		_, _ = EnqueueAll([]*Queue{orders, products}, original)
+		message := &Message{Id: uuid.New(), Payload: "Hello", DeduplicationId: "hello-1"}
+
+		enqueued, err := EnqueueAll([]*Queue{orders, products}, message)
+
+		assert.Nil(t, err)
+		assert.Len(t, enqueued, 0)
+		assert.True(t, message.Duplicate)
+		assert.Equal(t, original.Id, message.Id)
+	})
+
+	t.Run("Enqueues message into no queue when any journal fails", func(t *testing.T) {
+		ordersJournal := &failingJournal{}
+		orders := &Queue{Name: "orders", Durability: Durability.DURABLE, journal: ordersJournal}
+		products := &Queue{Name: "products", Durability: Durability.DURABLE, journal: &failingJournal{fail: true}}
+		message := &Message{Id: uuid.New(), Payload: "Hello"}
+
+		_, err := EnqueueAll([]*Queue{products, orders}, message)
+
+		assert.NotNil(t, err)
+		assert.Len(t, orders.GetMessages(), 0)
+		assert.Len(t, products.GetMessages(), 0)
+		assert.Len(t, ordersJournal.appended, 0)
+	})
+}
+
+func TestQueueMessageExpiry(t *testing.T) {
+	expire := func(message *Message) {
+		expiresAt := time.Now().Add(-time.Second)
+		message.ExpiresAt = &expiresAt
+	}
+
+	t.Run("Messages expire after the shortest of their expiration & the queue message TTL", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, MessageTtl: 60}
+
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Queue TTL"})
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Shorter expiration", Expiration: 10})
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Longer expiration", Expiration: 3600})
+
+		assert.WithinDuration(t, time.Now().Add(time.Minute), *q.GetMessages()[0].ExpiresAt, time.Second)
+		assert.WithinDuration(t, time.Now().Add(10*time.Second), *q.GetMessages()[1].ExpiresAt, time.Second)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), *q.GetMessages()[2].ExpiresAt, time.Second)
+	})
+
+	t.Run("Messages without expiration nor queue message TTL never expire", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Hello"})
+
+		assert.Nil(t, q.GetMessages()[0].ExpiresAt)
+	})
+
+	t.Run("Expired messages are removed instead of being dequeued or peeked", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Expired"})
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Alive"})
+		expire(q.GetMessages()[0])
+
+		peeked, _ := q.Peek(10)
+		assert.Len(t, peeked, 1)
+		assert.Equal(t, "Alive", peeked[0].Payload)
+
+		expire(q.GetMessages()[0])
+		assert.Nil(t, q.Dequeue())
+		assert.Len(t, q.GetMessages(), 0)
+	})
+
+	t.Run("Messages being processed do not expire", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Hello"})
+		message := q.Dequeue()
+		expire(message)
+
+		assert.Len(t, q.TakeDeadLetters(), 0)
+		assert.Len(t, q.GetMessages(), 1)
+		assert.Nil(t, q.Ack(message.Id))
+	})
+
+	t.Run("Expired messages are handed out to be dead-lettered when required", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, DeadLetterExpired: true}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Expired on dequeue"})
+		expire(q.GetMessages()[0])
+		assert.Nil(t, q.Dequeue())
+
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Expired on sweep"})
+		expire(q.GetMessages()[0])
+		expired := q.TakeDeadLetters()
+
+		assert.Len(t, expired, 2)
+		assert.Equal(t, "Expired on dequeue", expired[0].Message.Payload)
+		assert.Equal(t, "Expired on sweep", expired[1].Message.Payload)
+		assert.Equal(t, DeadLetterReasons.EXPIRED, expired[1].Reason)
+		assert.Len(t, q.GetMessages(), 0)
+		assert.Len(t, q.TakeDeadLetters(), 0)
+	})
+
+	t.Run("Expired messages are dropped when not required to be dead-lettered", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Expired"})
+		expire(q.GetMessages()[0])
+
+		assert.Len(t, q.TakeDeadLetters(), 0)
+		assert.Len(t, q.GetMessages(), 0)
+	})
+}
+
+func TestQueueOverflow(t *testing.T) {
+	enqueue := func(q *Queue, payloads ...string) {
+		for _, payload := range payloads {
+			_ = q.Enqueue(&Message{Id: uuid.New(), Payload: payload})
+		}
+	}
+	payloads := func(q *Queue) []string {
+		result := make([]string, len(q.GetMessages()))
+		for i, m := range q.GetMessages() {
+			result[i] = m.Payload
+		}
+
+		return result
+	}
+
+	t.Run("Oldest messages are dropped once max length is exceeded", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, MaxLength: 2}
+
+		enqueue(q, "1", "2", "3")
+
+		assert.Equal(t, []string{"2", "3"}, payloads(q))
+		assert.Len(t, q.TakeDeadLetters(), 0)
+	})
+
+	t.Run("Oldest messages are dropped once max bytes is exceeded", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, MaxBytes: 10, Overflow: OverflowPolicies.DROP_HEAD}
+
+		enqueue(q, "12345", "12345", "123")
+
+		assert.Equal(t, []string{"12345", "123"}, payloads(q))
+	})
+
+	t.Run("Messages being processed are neither counted nor dropped", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, MaxLength: 1}
+		enqueue(q, "1")
+		processing := q.Dequeue()
+
+		enqueue(q, "2", "3")
+
+		assert.Equal(t, []string{"1", "3"}, payloads(q))
+		assert.Nil(t, q.Ack(processing.Id))
+	})
+
+	t.Run("Publishing is rejected once the queue is full", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, MaxLength: 2, Overflow: OverflowPolicies.REJECT_PUBLISH}
+		enqueue(q, "1", "2")
+
+		err := q.Enqueue(&Message{Id: uuid.New(), Payload: "3"})
+
+		assert.Equal(t, errs.QueueFullErrorCode, err.GetCode())
+		assert.Equal(t, []string{"1", "2"}, payloads(q))
 	})
+
+	t.Run("Publishing to several queues is rejected when any of them is full", func(t *testing.T) {
+		orders := &Queue{Name: "orders", Durability: Durability.DURABLE}
+		products := &Queue{Name: "products", Durability: Durability.DURABLE, MaxBytes: 3, Overflow: OverflowPolicies.REJECT_PUBLISH}
+
+		_, err := EnqueueAll([]*Queue{orders, products}, &Message{Id: uuid.New(), Payload: "1234"})
+
+		assert.Equal(t, errs.QueueFullErrorCode, err.GetCode())
+		assert.Len(t, orders.GetMessages(), 0)
+		assert.Len(t, products.GetMessages(), 0)
+	})
+
+	t.Run("Delayed messages count towards the limits of queues rejecting publishes", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, MaxLength: 1, Overflow: OverflowPolicies.REJECT_PUBLISH}
+		deliverAt := time.Now().Add(20 * time.Millisecond)
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Delayed", DeliverAt: &deliverAt})
+
+		err := q.Enqueue(&Message{Id: uuid.New(), Payload: "Ready"})
+
+		assert.Equal(t, errs.QueueFullErrorCode, err.GetCode())
+		assert.Equal(t, []string{"Delayed"}, payloads(q))
+	})
+
+	t.Run("Delayed messages coming due don't drop messages of queues rejecting publishes", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, MaxLength: 1, Overflow: OverflowPolicies.REJECT_PUBLISH}
+		enqueue(q, "First")
+		first := q.Dequeue()
+		deliverAt := time.Now().Add(10 * time.Millisecond)
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Delayed", DeliverAt: &deliverAt})
+		_, _ = q.Nack(first.Id, true, 0)
+
+		time.Sleep(20 * time.Millisecond)
+
+		assert.Equal(t, []string{"First", "Delayed"}, payloads(q))
+	})
+
+	t.Run("Delayed messages coming due hand out the oldest messages to be dead-lettered once max length is exceeded", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, MaxLength: 1, Overflow: OverflowPolicies.DEAD_LETTER_HEAD}
+		deliverAt := time.Now().Add(10 * time.Millisecond)
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Delayed", DeliverAt: &deliverAt})
+		enqueue(q, "First")
+
+		time.Sleep(20 * time.Millisecond)
+
+		deadLetters := q.TakeDeadLetters()
+		assert.Len(t, deadLetters, 1)
+		assert.Equal(t, "First", deadLetters[0].Message.Payload)
+		assert.Equal(t, []string{"Delayed"}, payloads(q))
+	})
+
+	t.Run("Oldest messages are handed out to be dead-lettered once max length is exceeded", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, MaxLength: 1, Overflow: OverflowPolicies.DEAD_LETTER_HEAD}
+
+		enqueue(q, "1", "2", "3")
+
+		assert.Equal(t, []string{"3"}, payloads(q))
+		deadLetters := q.TakeDeadLetters()
+		assert.Len(t, deadLetters, 2)
+		assert.Equal(t, "1", deadLetters[0].Message.Payload)
+		assert.Equal(t, DeadLetterReasons.REJECTED_OVER_LIMIT, deadLetters[0].Reason)
+		assert.Equal(t, "2", deadLetters[1].Message.Payload)
+	})
+}
+
+func TestQueuePriority(t *testing.T) {
+	enqueue := func(q *Queue, payload string, priority int) {
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: payload, Priority: priority})
+	}
+
+	t.Run("Delivers highest priority messages first, in publishing order within a priority", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, MaxPriority: 10}
+		enqueue(q, "catalog 1", 0)
+		enqueue(q, "cancellation 1", 5)
+		enqueue(q, "catalog 2", 0)
+		enqueue(q, "urgent", 9)
+		enqueue(q, "cancellation 2", 5)
+
+		peeked, _ := q.Peek(1)
+		assert.Equal(t, "urgent", peeked[0].Payload)
+		for _, expected := range []string{"urgent", "cancellation 1", "cancellation 2", "catalog 1", "catalog 2"} {
+			assert.Equal(t, expected, q.Dequeue().Payload)
+		}
+	})
+
+	t.Run("Caps message priority to the queue max priority", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, MaxPriority: 3}
+		enqueue(q, "first", 3)
+		enqueue(q, "second", 200)
+
+		assert.Equal(t, "first", q.Dequeue().Payload)
+		assert.Equal(t, "second", q.Dequeue().Payload)
+	})
+
+	t.Run("Ignores message priority when the queue has no max priority", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		enqueue(q, "first", 0)
+		enqueue(q, "second", 9)
+
+		assert.Equal(t, "first", q.Dequeue().Payload)
+		assert.Equal(t, "second", q.Dequeue().Payload)
+	})
+
+	t.Run("Delivers requeued messages again ahead of lower priorities", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, MaxPriority: 10}
+		enqueue(q, "high", 5)
+		enqueue(q, "low", 1)
+
+		high := q.Dequeue()
+		_, nackErr := q.Nack(high.Id, true, 0)
+
+		assert.Nil(t, nackErr)
+		assert.Equal(t, "high", q.Dequeue().Payload)
+	})
+}
+
+func TestQueueDelayedDelivery(t *testing.T) {
+	deliverIn := func(delay time.Duration) *time.Time {
+		deliverAt := time.Now().Add(delay)
+		return &deliverAt
+	}
+
+	t.Run("Holds delayed messages invisible until due", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Reminder", DeliverAt: deliverIn(50 * time.Millisecond)})
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Now"})
+
+		assert.Equal(t, "Now", q.Dequeue().Payload)
+		assert.Nil(t, q.Dequeue())
+
+		time.Sleep(60 * time.Millisecond)
+		reminder := q.Dequeue()
+		assert.Equal(t, "Reminder", reminder.Payload)
+		assert.False(t, reminder.Delayed)
+	})
+
+	t.Run("Delays messages by the requested seconds", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		message := &Message{Id: uuid.New(), Payload: "Retry", Delay: 60}
+
+		_ = q.Enqueue(message)
+
+		assert.True(t, message.Delayed)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), *message.DeliverAt, time.Second)
+		assert.Nil(t, q.Dequeue())
+	})
+
+	t.Run("Delivers messages due in the past right away", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Late", DeliverAt: deliverIn(-time.Minute)})
+
+		assert.Equal(t, "Late", q.Dequeue().Payload)
+	})
+
+	t.Run("Peeks delayed messages after ready ones, the earliest due first", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "In an hour", DeliverAt: deliverIn(time.Hour)})
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "In a minute", DeliverAt: deliverIn(time.Minute)})
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Now"})
+
+		messages, _ := q.Peek(10)
+
+		assert.Len(t, messages, 3)
+		assert.Equal(t, "Now", messages[0].Payload)
+		assert.False(t, messages[0].Delayed)
+		assert.Equal(t, "In a minute", messages[1].Payload)
+		assert.True(t, messages[1].Delayed)
+		assert.Equal(t, "In an hour", messages[2].Payload)
+		assert.True(t, messages[2].Delayed)
+	})
+
+	t.Run("Peeks only the earliest due delayed messages up to the limit", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		for _, minutes := range []int{7, 3, 9, 1, 5, 8, 2, 6, 4} {
+			_ = q.Enqueue(&Message{Id: uuid.New(), Payload: fmt.Sprintf("In %d minutes", minutes), DeliverAt: deliverIn(time.Duration(minutes) * time.Minute)})
+		}
+
+		messages, _ := q.Peek(4)
+
+		assert.Len(t, messages, 4)
+		for i, message := range messages {
+			assert.Equal(t, fmt.Sprintf("In %d minutes", i+1), message.Payload)
+		}
+	})
+
+	t.Run("Delivers due messages in due order", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Second", DeliverAt: deliverIn(40 * time.Millisecond)})
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "First", DeliverAt: deliverIn(20 * time.Millisecond)})
+
+		time.Sleep(50 * time.Millisecond)
+
+		assert.Equal(t, "First", q.Dequeue().Payload)
+		assert.Equal(t, "Second", q.Dequeue().Payload)
+	})
+
+	t.Run("Starts the time to live once the message is due", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, MessageTtl: 60}
+		message := &Message{Id: uuid.New(), Payload: "Reminder", Delay: 3600}
+
+		_ = q.Enqueue(message)
+
+		assert.WithinDuration(t, message.DeliverAt.Add(time.Minute), *message.ExpiresAt, time.Millisecond)
+	})
+
+	t.Run("Purges delayed messages", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Reminder", Delay: 60})
+
+		assert.Nil(t, q.Purge())
+
+		messages, _ := q.Peek(10)
+		assert.Len(t, messages, 0)
+	})
+}
+
+func TestQueueInFlight(t *testing.T) {
+	payloads := func(messages []*Message) []string {
+		result := make([]string, len(messages))
+		for i, m := range messages {
+			result[i] = m.Payload
+		}
+
+		return result
+	}
+
+	t.Run("Redelivers messages once their visibility timeout is over, in publishing order", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		for i := 1; i <= 3; i++ {
+			_ = q.Enqueue(&Message{Id: uuid.New(), Payload: fmt.Sprintf("Message %d", i)})
+		}
+		first := q.DequeueWithVisibilityTimeout(20 * time.Millisecond)
+		second := q.DequeueWithVisibilityTimeout(10 * time.Millisecond)
+
+		time.Sleep(30 * time.Millisecond)
+
+		assert.Equal(t, []string{"Message 1", "Message 2", "Message 3"}, payloads(q.DequeueBatch(3, time.Minute)))
+		assert.Equal(t, 2, first.DeliveryCount)
+		assert.Equal(t, 2, second.DeliveryCount)
+	})
+
+	t.Run("Hands out messages to be dead-lettered once their visibility timeout is over for the last allowed delivery", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, MaxDeliveries: 2}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Hello"})
+		for i := 0; i < 2; i++ {
+			_ = q.DequeueWithVisibilityTimeout(time.Millisecond)
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		assert.Nil(t, q.Dequeue())
+		assert.Len(t, q.GetMessages(), 0)
+		deadLetters := q.TakeDeadLetters()
+		assert.Len(t, deadLetters, 1)
+		assert.Equal(t, "Hello", deadLetters[0].Message.Payload)
+		assert.Equal(t, 2, deadLetters[0].Message.DeliveryCount)
+		assert.Equal(t, DeadLetterReasons.MAX_DELIVERIES, deadLetters[0].Reason)
+	})
+
+	t.Run("Reclaims & redelivers a message whose visibility timeout is over in a single dequeue", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Hello"})
+		first := q.DequeueWithVisibilityTimeout(time.Millisecond)
+
+		time.Sleep(5 * time.Millisecond)
+		redelivered := q.DequeueWithVisibilityTimeout(time.Minute)
+
+		assert.Equal(t, first.Id, redelivered.Id)
+		assert.Equal(t, 2, redelivered.DeliveryCount)
+		assert.True(t, redelivered.IsProcessing())
+		assert.WithinDuration(t, time.Now().Add(time.Minute), redelivered.ProcessingUntil, time.Second)
+		assert.Nil(t, q.Ack(redelivered.Id))
+	})
+
+	t.Run("Returns not found when acknowledging a message whose visibility timeout is over", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Hello"})
+		message := q.DequeueWithVisibilityTimeout(time.Millisecond)
+
+		time.Sleep(5 * time.Millisecond)
+
+		assert.Equal(t, errs.MessageNotFoundErrorCode, q.Ack(message.Id).GetCode())
+		assert.False(t, q.GetMessages()[0].IsProcessing())
+	})
+
+	t.Run("Releases messages back ahead of the other messages", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Message 1"})
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Message 2"})
+		released := q.Dequeue()
+
+		q.Release(released.Id)
+
+		assert.False(t, released.IsProcessing())
+		assert.Equal(t, errs.MessageNotFoundErrorCode, q.Ack(released.Id).GetCode())
+		assert.Equal(t, "Message 1", q.Dequeue().Payload)
+	})
+
+	t.Run("Lists messages being processed in publishing order whatever their delivery order", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		for i := 1; i <= 4; i++ {
+			_ = q.Enqueue(&Message{Id: uuid.New(), Payload: fmt.Sprintf("Message %d", i)})
+		}
+		first := q.Dequeue()
+		second := q.Dequeue()
+		q.Release(first.Id)
+		_ = q.DequeueBatch(2, 0)
+		_, _ = q.Nack(second.Id, true, 0)
+		_ = q.Dequeue()
+
+		messages, _ := q.Peek(3)
+
+		assert.Equal(t, []string{"Message 1", "Message 2", "Message 3"}, payloads(messages))
+		assert.True(t, messages[0].IsProcessing())
+		assert.True(t, messages[1].IsProcessing())
+		assert.True(t, messages[2].IsProcessing())
+	})
+
+	t.Run("Lists messages being processed first", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		for i := 1; i <= 3; i++ {
+			_ = q.Enqueue(&Message{Id: uuid.New(), Payload: fmt.Sprintf("Message %d", i)})
+		}
+		_ = q.DequeueBatch(2, 0)
+
+		messages := q.GetMessages()
+
+		assert.Equal(t, []string{"Message 1", "Message 2", "Message 3"}, payloads(messages))
+		assert.True(t, messages[0].IsProcessing())
+		assert.True(t, messages[1].IsProcessing())
+		assert.False(t, messages[2].IsProcessing())
+	})
+}
+
+func TestQueueDeduplication(t *testing.T) {
+	t.Run("Drops messages published again within the deduplication window", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, DeduplicationWindow: 60}
+		original := &Message{Id: uuid.New(), Payload: "Order placed", DeduplicationId: "order-42"}
+		_ = q.Enqueue(original)
+
+		duplicate := &Message{Id: uuid.New(), Payload: "Order placed", DeduplicationId: "order-42"}
+		err := q.Enqueue(duplicate)
+
+		assert.Nil(t, err)
+		assert.True(t, duplicate.Duplicate)
+		assert.Equal(t, original.Id, duplicate.Id)
+		assert.False(t, original.Duplicate)
+		assert.Len(t, q.GetMessages(), 1)
+	})
+
+	t.Run("Keeps messages with different or no deduplication ids", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, DeduplicationWindow: 60}
+
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Order placed", DeduplicationId: "order-42"})
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Order placed", DeduplicationId: "order-43"})
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Order placed"})
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Order placed"})
+
+		assert.Len(t, q.GetMessages(), 4)
+	})
+
+	t.Run("Keeps duplicates when the queue has no deduplication window", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Order placed", DeduplicationId: "order-42"})
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Order placed", DeduplicationId: "order-42"})
+
+		assert.Len(t, q.GetMessages(), 2)
+	})
+
+	t.Run("Accepts the message again once its publishing failed", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, DeduplicationWindow: 60, MaxLength: 1, Overflow: OverflowPolicies.REJECT_PUBLISH}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Order placed", DeduplicationId: "order-41"})
+
+		assert.Equal(t, errs.QueueFullErrorCode, q.Enqueue(&Message{Id: uuid.New(), Payload: "Order placed", DeduplicationId: "order-42"}).GetCode())
+		assert.Nil(t, q.Ack(q.Dequeue().Id))
+
+		retried := &Message{Id: uuid.New(), Payload: "Order placed", DeduplicationId: "order-42"}
+		assert.Nil(t, q.Enqueue(retried))
+		assert.False(t, retried.Duplicate)
+		assert.Len(t, q.GetMessages(), 1)
+	})
+
+	t.Run("Forgets deduplication ids once the window is over", func(t *testing.T) {
+		var cache deduplicationCache
+		now := time.Now()
+		originalId := uuid.New()
+		cache.remember("order-42", originalId, now, time.Minute)
+
+		sameId, duplicate := cache.remember("order-42", uuid.New(), now.Add(59*time.Second), time.Minute)
+		assert.True(t, duplicate)
+		assert.Equal(t, originalId, sameId)
+
+		newId := uuid.New()
+		sameId, duplicate = cache.remember("order-42", newId, now.Add(time.Minute), time.Minute)
+		assert.False(t, duplicate)
+		assert.Equal(t, newId, sameId)
+		assert.Len(t, cache.entries, 1)
+	})
+}
+
+// Per operation cost must not depend on the queue depth.
+func TestQueueMessageGroups(t *testing.T) {
+	payloads := func(messages []*Message) []string {
+		result := make([]string, len(messages))
+		for i, m := range messages {
+			result[i] = m.Payload
+		}
+
+		return result
+	}
+	publish := func(q *Queue, groupId string, payload string) {
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: payload, GroupId: groupId})
+	}
+
+	t.Run("Delivers a single message per group at a time, across groups in parallel", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		publish(q, "product-1", "Product 1 created")
+		publish(q, "product-1", "Product 1 updated")
+		publish(q, "product-2", "Product 2 created")
+		publish(q, "", "Ungrouped")
+
+		assert.Equal(t, []string{"Product 1 created", "Product 2 created", "Ungrouped"}, payloads(q.DequeueBatch(10, 0)))
+		assert.Nil(t, q.Dequeue())
+	})
+
+	t.Run("Delivers the next message of a group once the previous one is acknowledged", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		for i := 1; i <= 3; i++ {
+			publish(q, "product-1", fmt.Sprintf("Message %d", i))
+		}
+
+		for i := 1; i <= 3; i++ {
+			message := q.Dequeue()
+			assert.Equal(t, fmt.Sprintf("Message %d", i), message.Payload)
+			assert.Nil(t, q.Dequeue())
+			assert.Nil(t, q.Ack(message.Id))
+		}
+		assert.Len(t, q.GetMessages(), 0)
+	})
+
+	t.Run("Keeps the group order when a message is requeued or its visibility timeout is over", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		publish(q, "product-1", "Message 1")
+		publish(q, "product-1", "Message 2")
+
+		_, _ = q.Nack(q.Dequeue().Id, true, 0)
+		assert.Equal(t, "Message 1", q.DequeueWithVisibilityTimeout(time.Millisecond).Payload)
+
+		time.Sleep(5 * time.Millisecond)
+
+		assert.Equal(t, []string{"Message 1"}, payloads(q.DequeueBatch(10, 0)))
+	})
+
+	t.Run("Delivers the next message of a group once the previous one is dead-lettered", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		publish(q, "product-1", "Message 1")
+		publish(q, "product-1", "Message 2")
+
+		rejected, _ := q.Nack(q.Dequeue().Id, false, 0)
+
+		assert.Equal(t, "Message 1", rejected.Payload)
+		assert.Equal(t, "Message 2", q.Dequeue().Payload)
+	})
+
+	t.Run("Delivers the next message of a group once the previous one expired", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Expired", GroupId: "product-1", Expiration: 1})
+		publish(q, "product-1", "Alive")
+		expiresAt := time.Now().Add(-time.Second)
+		q.GetMessages()[0].ExpiresAt = &expiresAt
+
+		assert.Equal(t, "Alive", q.Dequeue().Payload)
+	})
+
+	t.Run("Counts the messages waiting for their group towards the queue limits", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE, MaxLength: 2}
+		publish(q, "product-1", "Message 1")
+		publish(q, "product-1", "Message 2")
+		publish(q, "product-1", "Message 3")
+
+		assert.Equal(t, []string{"Message 2", "Message 3"}, payloads(q.GetMessages()))
+		assert.Equal(t, "Message 2", q.Dequeue().Payload)
+	})
+
+	t.Run("Lists the messages waiting for their group in publishing order", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		publish(q, "product-1", "Message 1")
+		publish(q, "product-2", "Message 2")
+		publish(q, "product-1", "Message 3")
+		publish(q, "product-2", "Message 4")
+		publish(q, "product-1", "Message 5")
+		_ = q.Dequeue()
+
+		messages, err := q.Peek(10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Message 1", "Message 2", "Message 3", "Message 4", "Message 5"}, payloads(messages))
+	})
+
+	t.Run("Restores the group of messages being processed", func(t *testing.T) {
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		q.RestoreMessages([]*Message{
+			{Id: uuid.New(), Payload: "Message 2", GroupId: "product-1"},
+			{Id: uuid.New(), Payload: "Message 1", GroupId: "product-1", Processing: true, ProcessingUntil: time.Now().Add(time.Minute)},
+		})
+
+		assert.Nil(t, q.Dequeue())
+	})
+}
+
+func BenchmarkQueuePublishConsume(b *testing.B) {
+	for _, depth := range []int{1_000, 100_000, 1_000_000} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			q := &Queue{Name: "testQueue", Durability: Durability.TRANSIENT}
+			for i := 0; i < depth; i++ {
+				_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Hello world!"})
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Hello world!"})
+				message := q.Dequeue()
+				_ = q.Ack(message.Id)
+			}
+		})
+	}
+}
+
+// Per operation cost must not depend on the number of messages being processed either.
+func BenchmarkQueueAckInFlight(b *testing.B) {
+	for _, depth := range []int{1_000, 100_000, 1_000_000} {
+		b.Run(fmt.Sprintf("inFlight=%d", depth), func(b *testing.B) {
+			q := &Queue{Name: "testQueue", Durability: Durability.TRANSIENT}
+			inFlight := make([]uuid.UUID, 0, depth)
+			for i := 0; i < depth; i++ {
+				_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Hello world!"})
+				inFlight = append(inFlight, q.DequeueWithVisibilityTimeout(time.Hour).Id)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = q.Ack(inFlight[i%depth])
+				_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Hello world!"})
+				inFlight[i%depth] = q.DequeueWithVisibilityTimeout(time.Hour).Id
+			}
+		})
+	}
+}
+
+// Nor on messages being redelivered out of publishing order.
+func BenchmarkQueueRequeueRedeliver(b *testing.B) {
+	for _, depth := range []int{1_000, 100_000, 1_000_000} {
+		b.Run(fmt.Sprintf("inFlight=%d", depth), func(b *testing.B) {
+			q := &Queue{Name: "testQueue", Durability: Durability.TRANSIENT}
+			inFlight := make([]uuid.UUID, 0, depth)
+			for i := 0; i < depth; i++ {
+				_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Hello world!"})
+				inFlight = append(inFlight, q.DequeueWithVisibilityTimeout(time.Hour).Id)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = q.Nack(inFlight[i%depth], true, 0)
+				inFlight[i%depth] = q.DequeueWithVisibilityTimeout(time.Hour).Id
+			}
+		})
+	}
 }
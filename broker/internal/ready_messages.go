@@ -0,0 +1,79 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package internal
+
+import (
+	"container/list"
+)
+
+// readyMessages holds the messages ready to be delivered: one FIFO list per priority, delivered highest priority first
+type readyMessages struct {
+	levels []*list.List
+	length int
+	bytes  int
+}
+
+func (r *readyMessages) pushBack(message *Message, priority int) {
+	r.level(priority).PushBack(message)
+	r.length++
+	r.bytes += len(message.Payload)
+}
+
+func (r *readyMessages) pushFront(message *Message, priority int) {
+	r.level(priority).PushFront(message)
+	r.length++
+	r.bytes += len(message.Payload)
+}
+
+func (r *readyMessages) frontAt(priority int) *Message {
+	if priority >= len(r.levels) || r.levels[priority].Len() == 0 {
+		return nil
+	}
+
+	return r.levels[priority].Front().Value.(*Message)
+}
+
+func (r *readyMessages) removeFrontAt(priority int) *Message {
+	message := r.levels[priority].Remove(r.levels[priority].Front()).(*Message)
+	r.length--
+	r.bytes -= len(message.Payload)
+
+	return message
+}
+
+// front returns the next message to be delivered along with its priority
+func (r *readyMessages) front() (message *Message, priority int) {
+	for priority = len(r.levels) - 1; priority >= 0; priority-- {
+		if message = r.frontAt(priority); message != nil {
+			return message, priority
+		}
+	}
+
+	return nil, 0
+}
+
+// list returns up to limit messages in delivery order
+func (r *readyMessages) list(limit int) (messages []*Message) {
+	messages = make([]*Message, 0, min(limit, r.length))
+	for priority := len(r.levels) - 1; priority >= 0; priority-- {
+		for e := r.levels[priority].Front(); e != nil && len(messages) < limit; e = e.Next() {
+			messages = append(messages, e.Value.(*Message))
+		}
+	}
+
+	return messages
+}
+
+func (r *readyMessages) clear() {
+	*r = readyMessages{}
+}
+
+func (r *readyMessages) level(priority int) *list.List {
+	for len(r.levels) <= priority {
+		r.levels = append(r.levels, list.New())
+	}
+
+	return r.levels[priority]
+}
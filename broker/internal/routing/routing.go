@@ -0,0 +1,255 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package routing
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+	"github.com/melyouz/risala/broker/internal/storage"
+)
+
+func PublishToExchange(
+	queueRepository storage.QueueRepository,
+	exchangeRepository storage.ExchangeRepository,
+	exchange *internal.Exchange,
+	message *internal.Message,
+	mandatory bool,
+) (queueNames []string, err errs.AppError) {
+	message.Exchange = exchange.Name
+	message.Duplicate = false
+
+	if exchange.Deduplicate(message) {
+		return make([]string, 0), nil
+	}
+	defer func() {
+		if err != nil {
+			exchange.ForgetDeduplication(message)
+		}
+	}()
+
+	queues := routeToQueues(queueRepository, exchangeRepository, exchange, message, map[string]bool{})
+
+	if mandatory && len(queues) == 0 {
+		return make([]string, 0), errs.NewMessageUnroutableError(fmt.Sprintf("Message could not be routed to any Queue through Exchange '%s'", exchange.Name))
+	}
+
+	enqueued, publishErr := internal.EnqueueAll(queues, message)
+	if publishErr != nil {
+		return make([]string, 0), publishErr
+	}
+
+	// queues dropping the message as a duplicate are left out
+	queueNames = make([]string, 0, len(enqueued))
+	for _, queue := range enqueued {
+		queueNames = append(queueNames, queue.Name)
+	}
+
+	return queueNames, nil
+}
+
+// Bind adds the binding to the exchange, ruling out exchange-to-exchange bindings that would create a cycle: the check
+// & the binding happen atomically with respect to the other exchange-to-exchange bindings
+func Bind(exchangeRepository storage.ExchangeRepository, exchange *internal.Exchange, binding *internal.Binding) (err errs.AppError) {
+	if binding.Exchange == "" {
+		return exchange.Bind(binding)
+	}
+
+	unlock := exchangeRepository.LockBindings()
+	defer unlock()
+
+	// an exchange being created along with its bindings isn't stored yet, binding it to itself is still a cycle
+	if binding.Exchange == exchange.Name {
+		return errs.NewBindingCycleError(fmt.Sprintf("Binding to Exchange '%s' would create a cycle", binding.Exchange))
+	}
+
+	destination, destinationErr := exchangeRepository.GetExchange(binding.Exchange)
+	if destinationErr != nil {
+		return destinationErr
+	}
+
+	if ExchangeReaches(exchangeRepository, destination, exchange.Name) {
+		return errs.NewBindingCycleError(fmt.Sprintf("Binding to Exchange '%s' would create a cycle", binding.Exchange))
+	}
+
+	return exchange.Bind(binding)
+}
+
+func ExchangeReaches(exchangeRepository storage.ExchangeRepository, exchange *internal.Exchange, exchangeName string) bool {
+	return exchangeReaches(exchangeRepository, exchange, exchangeName, map[string]bool{})
+}
+
+func exchangeReaches(exchangeRepository storage.ExchangeRepository, exchange *internal.Exchange, exchangeName string, visited map[string]bool) bool {
+	if exchange.Name == exchangeName {
+		return true
+	}
+	if visited[exchange.Name] {
+		return false
+	}
+	visited[exchange.Name] = true
+
+	for _, binding := range exchange.GetBindings() {
+		if binding.Exchange == "" {
+			continue
+		}
+
+		destination, exchangeErr := exchangeRepository.GetExchange(binding.Exchange)
+		if exchangeErr == nil && exchangeReaches(exchangeRepository, destination, exchangeName, visited) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func routeToQueues(
+	queueRepository storage.QueueRepository,
+	exchangeRepository storage.ExchangeRepository,
+	exchange *internal.Exchange,
+	message *internal.Message,
+	visited map[string]bool,
+) (queues []*internal.Queue) {
+	queues = make([]*internal.Queue, 0)
+	// every exchange is visited at most once, so that binding & alternate exchange cycles can't loop
+	for !visited[exchange.Name] {
+		visited[exchange.Name] = true
+
+		for _, binding := range exchange.Route(message) {
+			if binding.Exchange != "" {
+				destination, exchangeErr := exchangeRepository.GetExchange(binding.Exchange)
+				if exchangeErr != nil {
+					// bindings to deleted exchanges are skipped as well
+					continue
+				}
+
+				queues = append(queues, routeToQueues(queueRepository, exchangeRepository, destination, message, visited)...)
+				continue
+			}
+
+			queue, queueErr := queueRepository.GetQueue(binding.Queue)
+			if queueErr != nil {
+				// bindings to deleted queues are skipped: the message is routed to the remaining queues only
+				continue
+			}
+
+			queues = append(queues, queue)
+		}
+
+		if len(queues) > 0 || exchange.AlternateExchange == "" {
+			break
+		}
+
+		// unroutable: fall back to the alternate exchange
+		alternateExchange, exchangeErr := exchangeRepository.GetExchange(exchange.AlternateExchange)
+		if exchangeErr != nil {
+			break
+		}
+		exchange = alternateExchange
+	}
+
+	return queues
+}
+
+// Nack negatively acknowledges a message, dead-lettering it unless requeued: a message requeued past its max
+// deliveries is dead-lettered too. A message failing to be dead-lettered is handed back to the queue for the next sweep
+func Nack(
+	queueRepository storage.QueueRepository,
+	exchangeRepository storage.ExchangeRepository,
+	queue *internal.Queue,
+	messageId uuid.UUID,
+	requeue bool,
+	delay time.Duration,
+) (err errs.AppError) {
+	message, nackErr := queue.Nack(messageId, requeue, delay)
+	if nackErr != nil {
+		return nackErr
+	}
+
+	if message == nil {
+		return nil
+	}
+
+	reason := internal.DeadLetterReasons.NACK
+	if requeue {
+		reason = internal.DeadLetterReasons.MAX_DELIVERIES
+	}
+
+	deadLetterErr := DeadLetter(queueRepository, exchangeRepository, queue, message, reason)
+	if deadLetterErr != nil {
+		queue.ReturnDeadLetters([]internal.PendingDeadLetter{{Message: message, Reason: reason}})
+	}
+
+	return nil
+}
+
+func SweepDeadLetters(queueRepository storage.QueueRepository, exchangeRepository storage.ExchangeRepository) (err errs.AppError) {
+	for _, queue := range queueRepository.FindQueues() {
+		// messages already left the queue: the ones failing to be dead-lettered are kept for the next sweep
+		var failed []internal.PendingDeadLetter
+		for _, deadLetter := range queue.TakeDeadLetters() {
+			deadLetterErr := DeadLetter(queueRepository, exchangeRepository, queue, deadLetter.Message, deadLetter.Reason)
+			if deadLetterErr != nil {
+				failed = append(failed, deadLetter)
+				if err == nil {
+					err = deadLetterErr
+				}
+			}
+		}
+		queue.ReturnDeadLetters(failed)
+	}
+
+	return err
+}
+
+// EnsureDeadLetterQueue creates the system dead-letter queue unless it exists, so that messages can always be
+// dead-lettered
+func EnsureDeadLetterQueue(queueRepository storage.QueueRepository) (err errs.AppError) {
+	if _, queueErr := queueRepository.GetQueue(internal.DeadLetterQueueName); queueErr == nil {
+		return nil
+	}
+
+	return queueRepository.StoreQueue(&internal.Queue{
+		Name:       internal.DeadLetterQueueName,
+		Durability: internal.Durability.DURABLE,
+		System:     true,
+	})
+}
+
+func DeadLetter(
+	queueRepository storage.QueueRepository,
+	exchangeRepository storage.ExchangeRepository,
+	queue *internal.Queue,
+	message *internal.Message,
+	reason internal.DeadLetterReason,
+) (err errs.AppError) {
+	deadLetter := message.ToDeadLetter(queue.Name, reason)
+
+	if queue.DeadLetterExchange != "" {
+		exchange, exchangeErr := exchangeRepository.GetExchange(queue.DeadLetterExchange)
+		if exchangeErr == nil {
+			if queue.DeadLetterRoutingKey != "" {
+				deadLetter.RoutingKey = queue.DeadLetterRoutingKey
+			}
+
+			queueNames, publishErr := PublishToExchange(queueRepository, exchangeRepository, exchange, deadLetter, false)
+			if publishErr != nil || len(queueNames) > 0 {
+				return publishErr
+			}
+		}
+	}
+
+	// no (routable) dead-letter exchange: fall back to the system dead-letter queue
+	deadLetterQueue, deadLetterQueueErr := queueRepository.GetQueue(internal.DeadLetterQueueName)
+	if deadLetterQueueErr != nil {
+		return deadLetterQueueErr
+	}
+
+	deadLetter.Exchange = ""
+	return deadLetterQueue.Enqueue(deadLetter)
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package routing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+	"github.com/melyouz/risala/broker/internal/storage"
+	"github.com/melyouz/risala/broker/internal/testing/util"
+)
+
+func TestBind(t *testing.T) {
+	t.Run("Checks cycles once the exchange-to-exchange bindings in progress are done", func(t *testing.T) {
+		exchanges := map[string]*internal.Exchange{
+			"product": util.NewTestExchangeWithoutBindings("product"),
+			"order":   util.NewTestExchangeWithoutBindings("order"),
+		}
+		exchangeRepository := storage.NewInMemoryExchangeRepository(exchanges)
+
+		unlock := exchangeRepository.LockBindings()
+		bound := make(chan errs.AppError)
+		go func() {
+			bound <- Bind(exchangeRepository, exchanges["product"], &internal.Binding{Id: uuid.New(), Exchange: "order", RoutingKey: "#"})
+		}()
+		time.Sleep(10 * time.Millisecond)
+		assert.Nil(t, exchanges["order"].Bind(&internal.Binding{Id: uuid.New(), Exchange: "product", RoutingKey: "#"}))
+		unlock()
+
+		bindErr := <-bound
+		assert.NotNil(t, bindErr)
+		assert.Equal(t, errs.BindingCycleErrorCode, bindErr.GetCode())
+		assert.Empty(t, exchanges["product"].GetBindings())
+	})
+}
+
+func TestSweepDeadLetters(t *testing.T) {
+	expire := func(message *internal.Message) {
+		expiresAt := time.Now().Add(-time.Second)
+		message.ExpiresAt = &expiresAt
+	}
+
+	t.Run("Dead-letters expired messages when required", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events":                     {Name: "events", Durability: internal.Durability.DURABLE, DeadLetterExpired: true},
+			"tmp":                        util.NewTestQueueTransientWithoutMessages("tmp"),
+			internal.DeadLetterQueueName: util.NewTestSystemQueueWithoutMessages(internal.DeadLetterQueueName),
+		}
+		_ = queues["events"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Expired", RoutingKey: "product.created"})
+		_ = queues["events"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Alive"})
+		_ = queues["tmp"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Expired"})
+		expire(queues["events"].GetMessages()[0])
+		expire(queues["tmp"].GetMessages()[0])
+
+		err := SweepDeadLetters(storage.NewInMemoryQueueRepository(queues), storage.NewInMemoryExchangeRepository(map[string]*internal.Exchange{}))
+
+		assert.Nil(t, err)
+		assert.Len(t, queues["events"].GetMessages(), 1)
+		assert.Equal(t, "Alive", queues["events"].GetMessages()[0].Payload)
+		assert.Len(t, queues["tmp"].GetMessages(), 0)
+		deadLetters := queues[internal.DeadLetterQueueName].GetMessages()
+		assert.Len(t, deadLetters, 1)
+		assert.Equal(t, "Expired", deadLetters[0].Payload)
+		assert.Equal(t, "expired", deadLetters[0].Headers[internal.DeathReasonHeader])
+		assert.Equal(t, "events", deadLetters[0].Headers[internal.DeathQueueHeader])
+		assert.Nil(t, deadLetters[0].ExpiresAt)
+	})
+
+	t.Run("Keeps messages failing to be dead-lettered for the next sweep", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": {Name: "events", Durability: internal.Durability.DURABLE, DeadLetterExpired: true},
+		}
+		queueRepository := storage.NewInMemoryQueueRepository(queues)
+		exchangeRepository := storage.NewInMemoryExchangeRepository(map[string]*internal.Exchange{})
+		_ = queues["events"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Expired"})
+		expire(queues["events"].GetMessages()[0])
+
+		failedErr := SweepDeadLetters(queueRepository, exchangeRepository)
+		assert.Nil(t, EnsureDeadLetterQueue(queueRepository))
+		err := SweepDeadLetters(queueRepository, exchangeRepository)
+
+		assert.NotNil(t, failedErr)
+		assert.Nil(t, err)
+		assert.Len(t, queues["events"].GetMessages(), 0)
+		deadLetters := queues[internal.DeadLetterQueueName].GetMessages()
+		assert.Len(t, deadLetters, 1)
+		assert.Equal(t, "Expired", deadLetters[0].Payload)
+	})
+
+	t.Run("Dead-letters messages dropped from full queues", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events":                     {Name: "events", Durability: internal.Durability.DURABLE, MaxLength: 1, Overflow: internal.OverflowPolicies.DEAD_LETTER_HEAD},
+			internal.DeadLetterQueueName: util.NewTestSystemQueueWithoutMessages(internal.DeadLetterQueueName),
+		}
+		_ = queues["events"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Dropped"})
+		_ = queues["events"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Kept"})
+
+		err := SweepDeadLetters(storage.NewInMemoryQueueRepository(queues), storage.NewInMemoryExchangeRepository(map[string]*internal.Exchange{}))
+
+		assert.Nil(t, err)
+		assert.Len(t, queues["events"].GetMessages(), 1)
+		deadLetters := queues[internal.DeadLetterQueueName].GetMessages()
+		assert.Len(t, deadLetters, 1)
+		assert.Equal(t, "Dropped", deadLetters[0].Payload)
+		assert.Equal(t, "rejected-over-limit", deadLetters[0].Headers[internal.DeathReasonHeader])
+	})
+}
+
+func TestNack(t *testing.T) {
+	t.Run("Dead-letters messages requeued past their max deliveries", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events":                     {Name: "events", Durability: internal.Durability.DURABLE, MaxDeliveries: 1},
+			internal.DeadLetterQueueName: util.NewTestSystemQueueWithoutMessages(internal.DeadLetterQueueName),
+		}
+		_ = queues["events"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 1"})
+		message := queues["events"].Dequeue()
+
+		err := Nack(storage.NewInMemoryQueueRepository(queues), storage.NewInMemoryExchangeRepository(map[string]*internal.Exchange{}), queues["events"], message.Id, true, 0)
+
+		assert.Nil(t, err)
+		assert.Empty(t, queues["events"].GetMessages())
+		deadLetters := queues[internal.DeadLetterQueueName].GetMessages()
+		assert.Len(t, deadLetters, 1)
+		assert.Equal(t, "max-deliveries", deadLetters[0].Headers[internal.DeathReasonHeader])
+	})
+
+	t.Run("Keeps messages failing to be dead-lettered for the next sweep", func(t *testing.T) {
+		queues := map[string]*internal.Queue{
+			"events": {Name: "events", Durability: internal.Durability.DURABLE},
+		}
+		queueRepository := storage.NewInMemoryQueueRepository(queues)
+		exchangeRepository := storage.NewInMemoryExchangeRepository(map[string]*internal.Exchange{})
+		_ = queues["events"].Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 1"})
+		message := queues["events"].Dequeue()
+
+		err := Nack(queueRepository, exchangeRepository, queues["events"], message.Id, false, 0)
+		assert.Nil(t, EnsureDeadLetterQueue(queueRepository))
+		sweepErr := SweepDeadLetters(queueRepository, exchangeRepository)
+
+		assert.Nil(t, err)
+		assert.Nil(t, sweepErr)
+		deadLetters := queues[internal.DeadLetterQueueName].GetMessages()
+		assert.Len(t, deadLetters, 1)
+		assert.Equal(t, "nack", deadLetters[0].Headers[internal.DeathReasonHeader])
+	})
+}
+
+func TestEnsureDeadLetterQueue(t *testing.T) {
+	t.Run("Creates the system dead-letter queue when missing", func(t *testing.T) {
+		queueRepository := storage.NewInMemoryQueueRepository(map[string]*internal.Queue{})
+
+		err := EnsureDeadLetterQueue(queueRepository)
+
+		assert.Nil(t, err)
+		queue, queueErr := queueRepository.GetQueue(internal.DeadLetterQueueName)
+		assert.Nil(t, queueErr)
+		assert.True(t, queue.IsSystem())
+		assert.True(t, queue.IsDurable())
+	})
+
+	t.Run("Keeps the existing system dead-letter queue", func(t *testing.T) {
+		deadLetterQueue := util.NewTestSystemQueueWithoutMessages(internal.DeadLetterQueueName)
+		_ = deadLetterQueue.Enqueue(&internal.Message{Id: uuid.New(), Payload: "Dead letter"})
+		queueRepository := storage.NewInMemoryQueueRepository(map[string]*internal.Queue{internal.DeadLetterQueueName: deadLetterQueue})
+
+		err := EnsureDeadLetterQueue(queueRepository)
+
+		assert.Nil(t, err)
+		queue, _ := queueRepository.GetQueue(internal.DeadLetterQueueName)
+		assert.Len(t, queue.GetMessages(), 1)
+	})
+}
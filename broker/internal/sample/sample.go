@@ -11,10 +11,10 @@ import (
 )
 
 var Exchanges = map[string]*internal.Exchange{
-	"app.internal": {Name: "app.internal", Bindings: []*internal.Binding{
+	"app.internal": {Name: "app.internal", Type: internal.ExchangeTypes.TOPIC, Bindings: []*internal.Binding{
 		{Id: uuid.New(), Queue: "events", RoutingKey: "#"},
 	}},
-	"app.external": {Name: "app.external", Bindings: []*internal.Binding{
+	"app.external": {Name: "app.external", Type: internal.ExchangeTypes.TOPIC, Bindings: []*internal.Binding{
 		{Id: uuid.New(), Queue: "tmp", RoutingKey: "#"},
 	}},
 }
@@ -23,23 +23,26 @@ var Queues = map[string]*internal.Queue{
 	"events": {
 		Name:       "events",
 		Durability: internal.Durability.DURABLE,
-		Messages:   []*internal.Message{},
 	},
-	"tmp": {
+	"tmp": withMessages(&internal.Queue{
 		Name:       "tmp",
 		Durability: internal.Durability.TRANSIENT,
-		Messages: []*internal.Message{
-			{Id: uuid.New(), Payload: "Message 1 (tmp)"},
-			{Id: uuid.New(), Payload: "Message 2 (tmp)"},
-			{Id: uuid.New(), Payload: "Message 3 (tmp)"},
-			{Id: uuid.New(), Payload: "Message 4 (tmp)"},
-			{Id: uuid.New(), Payload: "Message 5 (tmp)"},
-		},
-	},
+	}, []*internal.Message{
+		{Id: uuid.New(), Payload: "Message 1 (tmp)"},
+		{Id: uuid.New(), Payload: "Message 2 (tmp)"},
+		{Id: uuid.New(), Payload: "Message 3 (tmp)"},
+		{Id: uuid.New(), Payload: "Message 4 (tmp)"},
+		{Id: uuid.New(), Payload: "Message 5 (tmp)"},
+	}),
 	internal.DeadLetterQueueName: {
 		Name:       internal.DeadLetterQueueName,
 		Durability: internal.Durability.DURABLE,
 		System:     true,
-		Messages:   []*internal.Message{},
 	},
 }
+
+func withMessages(queue *internal.Queue, messages []*internal.Message) *internal.Queue {
+	queue.RestoreMessages(messages)
+
+	return queue
+}
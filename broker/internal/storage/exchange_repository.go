@@ -10,8 +10,11 @@ import (
 )
 
 type ExchangeRepository interface {
-	StoreExchange(exchange *internal.Exchange)
+	StoreExchange(exchange *internal.Exchange) (err errs.AppError)
 	FindExchanges() []*internal.Exchange
 	GetExchange(name string) (queue *internal.Exchange, err errs.AppError)
 	DeleteExchange(name string) (err errs.AppError)
+	// LockBindings serializes the binding changes needing to look at other exchanges (e.g. to rule out cycles), until
+	// unlock is called
+	LockBindings() (unlock func())
 }
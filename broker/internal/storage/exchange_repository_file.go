@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+)
+
+const exchangesFileName = "exchanges.json"
+
+type FileExchangeRepository struct {
+	*InMemoryExchangeRepository
+	lock    *sync.Mutex
+	dataDir string
+}
+
+func NewFileExchangeRepository(dataDir string, exchangeList map[string]*internal.Exchange) (*FileExchangeRepository, errs.AppError) {
+	if mkdirErr := os.MkdirAll(dataDir, 0o755); mkdirErr != nil {
+		return nil, errs.NewStorageError(fmt.Sprintf("Error creating data directory '%s': %s", dataDir, mkdirErr))
+	}
+
+	r := &FileExchangeRepository{
+		InMemoryExchangeRepository: NewInMemoryExchangeRepository(exchangeList),
+		lock:                       &sync.Mutex{},
+		dataDir:                    dataDir,
+	}
+
+	var persistedExchanges []*internal.Exchange
+	_, readErr := readJSONFile(r.exchangesFilePath(), &persistedExchanges)
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	for _, exchange := range persistedExchanges {
+		if exchange.Bindings == nil {
+			exchange.Bindings = []*internal.Binding{}
+		}
+		r.ExchangeList[exchange.Name] = exchange
+	}
+
+	writeErr := r.writeExchanges()
+	if writeErr != nil {
+		return nil, writeErr
+	}
+
+	return r, nil
+}
+
+func (r *FileExchangeRepository) StoreExchange(exchange *internal.Exchange) (err errs.AppError) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	storeErr := r.InMemoryExchangeRepository.StoreExchange(exchange)
+	if storeErr != nil {
+		return storeErr
+	}
+
+	return r.writeExchanges()
+}
+
+func (r *FileExchangeRepository) DeleteExchange(name string) (err errs.AppError) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	deleteErr := r.InMemoryExchangeRepository.DeleteExchange(name)
+	if deleteErr != nil {
+		return deleteErr
+	}
+
+	return r.writeExchanges()
+}
+
+func (r *FileExchangeRepository) writeExchanges() (err errs.AppError) {
+	exchanges := r.FindExchanges()
+	for _, exchange := range exchanges {
+		exchange.RLock()
+		defer exchange.RUnlock()
+	}
+
+	return writeJSONFile(r.exchangesFilePath(), exchanges)
+}
+
+func (r *FileExchangeRepository) exchangesFilePath() string {
+	return filepath.Join(r.dataDir, exchangesFileName)
+}
@@ -0,0 +1,40 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/melyouz/risala/broker/internal"
+)
+
+func TestFileExchangeRepository(t *testing.T) {
+	t.Run("Restores exchanges & their bindings", func(t *testing.T) {
+		dataDir := t.TempDir()
+		r, err := NewFileExchangeRepository(dataDir, map[string]*internal.Exchange{})
+		assert.Nil(t, err)
+
+		exchange := &internal.Exchange{Name: "app.internal", Type: internal.ExchangeTypes.TOPIC, Bindings: []*internal.Binding{}}
+		assert.Nil(t, r.StoreExchange(exchange))
+		assert.Nil(t, exchange.Bind(&internal.Binding{Id: uuid.New(), Queue: "events", RoutingKey: "product.#"}))
+		assert.Nil(t, r.StoreExchange(exchange))
+		assert.Nil(t, r.StoreExchange(&internal.Exchange{Name: "app.tmp", Type: internal.ExchangeTypes.FANOUT, Bindings: []*internal.Binding{}}))
+		assert.Nil(t, r.DeleteExchange("app.tmp"))
+
+		restored, restoreErr := NewFileExchangeRepository(dataDir, map[string]*internal.Exchange{})
+		assert.Nil(t, restoreErr)
+
+		exchanges := restored.FindExchanges()
+		assert.Len(t, exchanges, 1)
+		assert.Equal(t, "app.internal", exchanges[0].Name)
+		assert.Equal(t, internal.ExchangeTypes.TOPIC, exchanges[0].Type)
+		assert.Len(t, exchanges[0].Bindings, 1)
+		assert.Equal(t, "events", exchanges[0].Bindings[0].Queue)
+		assert.Equal(t, "product.#", exchanges[0].Bindings[0].RoutingKey)
+	})
+}
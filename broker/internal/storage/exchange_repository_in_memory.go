@@ -15,12 +15,14 @@ import (
 
 type InMemoryExchangeRepository struct {
 	lock         *sync.RWMutex
+	bindingsLock *sync.Mutex
 	ExchangeList map[string]*internal.Exchange
 }
 
 func NewInMemoryExchangeRepository(exchangeList map[string]*internal.Exchange) *InMemoryExchangeRepository {
 	return &InMemoryExchangeRepository{
 		lock:         &sync.RWMutex{},
+		bindingsLock: &sync.Mutex{},
 		ExchangeList: exchangeList,
 	}
 }
@@ -42,11 +44,12 @@ func (r *InMemoryExchangeRepository) FindExchanges() []*internal.Exchange {
 	return result
 }
 
-func (r *InMemoryExchangeRepository) StoreExchange(exchange *internal.Exchange) {
+func (r *InMemoryExchangeRepository) StoreExchange(exchange *internal.Exchange) (err errs.AppError) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
 	r.ExchangeList[exchange.Name] = exchange
+	return nil
 }
 
 func (r *InMemoryExchangeRepository) GetExchange(name string) (exchange *internal.Exchange, err errs.AppError) {
@@ -73,3 +76,9 @@ func (r *InMemoryExchangeRepository) DeleteExchange(name string) (err errs.AppEr
 	delete(r.ExchangeList, name)
 	return nil
 }
+
+func (r *InMemoryExchangeRepository) LockBindings() (unlock func()) {
+	r.bindingsLock.Lock()
+
+	return r.bindingsLock.Unlock
+}
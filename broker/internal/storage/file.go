@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/melyouz/risala/broker/internal/errs"
+)
+
+func readJSONFile(path string, dst interface{}) (found bool, err errs.AppError) {
+	data, readErr := os.ReadFile(path)
+	if errors.Is(readErr, os.ErrNotExist) {
+		return false, nil
+	}
+	if readErr != nil {
+		return false, errs.NewStorageError(fmt.Sprintf("Error reading '%s': %s", path, readErr))
+	}
+
+	if decodeErr := json.Unmarshal(data, dst); decodeErr != nil {
+		return false, errs.NewStorageError(fmt.Sprintf("Error decoding '%s': %s", path, decodeErr))
+	}
+
+	return true, nil
+}
+
+func writeJSONFile(path string, src interface{}) (err errs.AppError) {
+	data, encodeErr := json.MarshalIndent(src, "", "  ")
+	if encodeErr != nil {
+		return errs.NewStorageError(fmt.Sprintf("Error encoding '%s': %s", path, encodeErr))
+	}
+
+	return writeFileAtomically(path, data)
+}
+
+func writeFileAtomically(path string, data []byte) (err errs.AppError) {
+	tmpFile, createErr := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if createErr != nil {
+		return errs.NewStorageError(fmt.Sprintf("Error creating temporary file for '%s': %s", path, createErr))
+	}
+	defer func() {
+		_ = os.Remove(tmpFile.Name())
+	}()
+
+	_, writeErr := tmpFile.Write(data)
+	if writeErr == nil {
+		writeErr = tmpFile.Sync()
+	}
+	closeErr := tmpFile.Close()
+	if writeErr != nil || closeErr != nil {
+		return errs.NewStorageError(fmt.Sprintf("Error writing '%s': %s", path, errors.Join(writeErr, closeErr)))
+	}
+
+	if renameErr := os.Rename(tmpFile.Name(), path); renameErr != nil {
+		return errs.NewStorageError(fmt.Sprintf("Error writing '%s': %s", path, renameErr))
+	}
+
+	return nil
+}
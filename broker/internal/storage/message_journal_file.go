@@ -0,0 +1,304 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+)
+
+const journalCompactionThreshold = 1000
+
+const journalOpAppend = "append"
+const journalOpRemove = "remove"
+const journalOpUpdate = "update"
+
+type journalRecord struct {
+	Op            string            `json:"op"`
+	Message       *internal.Message `json:"message,omitempty"`
+	MessageId     *uuid.UUID        `json:"messageId,omitempty"`
+	DeliveryCount int               `json:"deliveryCount,omitempty"`
+	AvailableAt   *time.Time        `json:"availableAt,omitempty"`
+}
+
+func newJournalAppendRecord(message *internal.Message) journalRecord {
+	record := journalRecord{Op: journalOpAppend, Message: message}
+	if availableAt := message.GetAvailableAt(); !availableAt.IsZero() {
+		record.AvailableAt = &availableAt
+	}
+
+	return record
+}
+
+// FileMessageJournal is an append-only log of the messages of a queue, compacted once most of its records are stale.
+// Records are handed to the OS as they are written, surviving a broker crash: they only survive an OS crash or a power
+// loss when writes are synced, at the cost of a disk flush per record.
+type FileMessageJournal struct {
+	lock       *sync.Mutex
+	path       string
+	syncWrites bool
+	file       *os.File
+	records    int
+	live       int
+}
+
+func NewFileMessageJournal(path string, syncWrites bool) *FileMessageJournal {
+	return &FileMessageJournal{
+		lock:       &sync.Mutex{},
+		path:       path,
+		syncWrites: syncWrites,
+	}
+}
+
+func (j *FileMessageJournal) Load() (messages []*internal.Message, err errs.AppError) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	return j.load()
+}
+
+func (j *FileMessageJournal) load() (messages []*internal.Message, err errs.AppError) {
+	file, openErr := os.Open(j.path)
+	if errors.Is(openErr, os.ErrNotExist) {
+		return []*internal.Message{}, nil
+	}
+	if openErr != nil {
+		return nil, errs.NewStorageError(fmt.Sprintf("Error reading journal '%s': %s", j.path, openErr))
+	}
+	defer file.Close()
+
+	messages = []*internal.Message{}
+	positions := map[uuid.UUID]int{}
+	reader := bufio.NewReader(file)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return nil, errs.NewStorageError(fmt.Sprintf("Error reading journal '%s': %s", j.path, readErr))
+		}
+		if len(line) == 0 {
+			break
+		}
+
+		var record journalRecord
+		if decodeErr := json.Unmarshal(line, &record); decodeErr != nil {
+			// a partially written last record (e.g. crash while appending) is discarded
+			break
+		}
+
+		switch {
+		case record.Op == journalOpAppend && record.Message != nil:
+			record.Message.Processing = false
+			if record.AvailableAt != nil {
+				record.Message.AvailableAt = *record.AvailableAt
+			}
+			positions[record.Message.Id] = len(messages)
+			messages = append(messages, record.Message)
+		case record.Op == journalOpUpdate && record.MessageId != nil:
+			if i, ok := positions[*record.MessageId]; ok {
+				messages[i].DeliveryCount = record.DeliveryCount
+				messages[i].AvailableAt = time.Time{}
+				if record.AvailableAt != nil {
+					messages[i].AvailableAt = *record.AvailableAt
+				}
+			}
+		case record.Op == journalOpRemove && record.MessageId != nil:
+			if i, ok := positions[*record.MessageId]; ok {
+				messages[i] = nil
+				delete(positions, *record.MessageId)
+			}
+		}
+	}
+
+	result := make([]*internal.Message, 0, len(positions))
+	for _, m := range messages {
+		if m != nil {
+			result = append(result, m)
+		}
+	}
+
+	return result, nil
+}
+
+func (j *FileMessageJournal) Reset(messages []*internal.Message) (err errs.AppError) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	return j.reset(messages)
+}
+
+func (j *FileMessageJournal) reset(messages []*internal.Message) (err errs.AppError) {
+	if j.file != nil {
+		_ = j.file.Close()
+		j.file = nil
+	}
+
+	var buffer bytes.Buffer
+	for _, m := range messages {
+		line, encodeErr := encodeJournalRecord(newJournalAppendRecord(m))
+		if encodeErr != nil {
+			return encodeErr
+		}
+		buffer.Write(line)
+	}
+
+	writeErr := writeFileAtomically(j.path, buffer.Bytes())
+	if writeErr != nil {
+		return writeErr
+	}
+
+	file, openErr := os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if openErr != nil {
+		return errs.NewStorageError(fmt.Sprintf("Error opening journal '%s': %s", j.path, openErr))
+	}
+
+	j.file = file
+	j.records = len(messages)
+	j.live = len(messages)
+
+	return nil
+}
+
+func (j *FileMessageJournal) Append(message *internal.Message) (err errs.AppError) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	writeErr := j.write(newJournalAppendRecord(message))
+	if writeErr != nil {
+		return writeErr
+	}
+
+	j.live++
+	return nil
+}
+
+func (j *FileMessageJournal) Remove(messageId uuid.UUID) (err errs.AppError) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	writeErr := j.write(journalRecord{Op: journalOpRemove, MessageId: &messageId})
+	if writeErr != nil {
+		return writeErr
+	}
+
+	j.live--
+	return j.compactIfStale()
+}
+
+func (j *FileMessageJournal) Update(messageId uuid.UUID, deliveryCount int, availableAt time.Time) (err errs.AppError) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	record := journalRecord{Op: journalOpUpdate, MessageId: &messageId, DeliveryCount: deliveryCount}
+	if !availableAt.IsZero() {
+		record.AvailableAt = &availableAt
+	}
+
+	writeErr := j.write(record)
+	if writeErr != nil {
+		return writeErr
+	}
+
+	return j.compactIfStale()
+}
+
+func (j *FileMessageJournal) Truncate() (err errs.AppError) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	return j.truncate()
+}
+
+func (j *FileMessageJournal) Delete() (err errs.AppError) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if j.file != nil {
+		_ = j.file.Close()
+		j.file = nil
+	}
+
+	removeErr := os.Remove(j.path)
+	if removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+		return errs.NewStorageError(fmt.Sprintf("Error deleting journal '%s': %s", j.path, removeErr))
+	}
+
+	return nil
+}
+
+func (j *FileMessageJournal) write(record journalRecord) (err errs.AppError) {
+	if j.file == nil {
+		return errs.NewStorageError(fmt.Sprintf("Journal '%s' is not open", j.path))
+	}
+
+	line, encodeErr := encodeJournalRecord(record)
+	if encodeErr != nil {
+		return encodeErr
+	}
+
+	_, writeErr := j.file.Write(line)
+	if writeErr == nil && j.syncWrites {
+		writeErr = j.file.Sync()
+	}
+	if writeErr != nil {
+		return errs.NewStorageError(fmt.Sprintf("Error writing journal '%s': %s", j.path, writeErr))
+	}
+
+	j.records++
+	return nil
+}
+
+// compactIfStale compacts the journal once most of its records are stale
+func (j *FileMessageJournal) compactIfStale() (err errs.AppError) {
+	if j.records >= journalCompactionThreshold && j.records > 2*j.live {
+		return j.compact()
+	}
+
+	return nil
+}
+
+// compact rewrites the journal with the records of the messages still in the queue only
+func (j *FileMessageJournal) compact() (err errs.AppError) {
+	messages, loadErr := j.load()
+	if loadErr != nil {
+		return loadErr
+	}
+
+	return j.reset(messages)
+}
+
+func (j *FileMessageJournal) truncate() (err errs.AppError) {
+	if j.file == nil {
+		return errs.NewStorageError(fmt.Sprintf("Journal '%s' is not open", j.path))
+	}
+
+	if truncateErr := j.file.Truncate(0); truncateErr != nil {
+		return errs.NewStorageError(fmt.Sprintf("Error truncating journal '%s': %s", j.path, truncateErr))
+	}
+
+	j.records = 0
+	j.live = 0
+	return nil
+}
+
+func encodeJournalRecord(record journalRecord) (line []byte, err errs.AppError) {
+	data, encodeErr := json.Marshal(record)
+	if encodeErr != nil {
+		return nil, errs.NewStorageError(fmt.Sprintf("Error encoding journal record: %s", encodeErr))
+	}
+
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+)
+
+const queuesFileName = "queues.json"
+const queueJournalsDirName = "queues"
+
+type FileQueueRepository struct {
+	*InMemoryQueueRepository
+	lock       *sync.Mutex
+	dataDir    string
+	syncWrites bool
+	journals   map[string]*FileMessageJournal
+}
+
+func NewFileQueueRepository(dataDir string, syncWrites bool, queueList map[string]*internal.Queue) (*FileQueueRepository, errs.AppError) {
+	if mkdirErr := os.MkdirAll(filepath.Join(dataDir, queueJournalsDirName), 0o755); mkdirErr != nil {
+		return nil, errs.NewStorageError(fmt.Sprintf("Error creating data directory '%s': %s", dataDir, mkdirErr))
+	}
+
+	r := &FileQueueRepository{
+		InMemoryQueueRepository: NewInMemoryQueueRepository(queueList),
+		lock:                    &sync.Mutex{},
+		dataDir:                 dataDir,
+		syncWrites:              syncWrites,
+		journals:                map[string]*FileMessageJournal{},
+	}
+
+	var persistedQueues []*internal.Queue
+	_, readErr := readJSONFile(r.queuesFilePath(), &persistedQueues)
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	for _, queue := range persistedQueues {
+		journal := NewFileMessageJournal(r.journalFilePath(queue.Name), syncWrites)
+		messages, loadErr := journal.Load()
+		if loadErr != nil {
+			return nil, loadErr
+		}
+
+		queue.RestoreMessages(messages)
+		r.QueueList[queue.Name] = queue
+	}
+
+	for _, queue := range r.QueueList {
+		if !queue.IsDurable() {
+			continue
+		}
+
+		attachErr := r.attachJournal(queue)
+		if attachErr != nil {
+			return nil, attachErr
+		}
+	}
+
+	writeErr := r.writeQueues()
+	if writeErr != nil {
+		return nil, writeErr
+	}
+
+	return r, nil
+}
+
+func (r *FileQueueRepository) StoreQueue(queue *internal.Queue) (err errs.AppError) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, getErr := r.GetQueue(queue.Name); getErr == nil {
+		return errs.NewQueueExistsError(fmt.Sprintf("Queue '%s' already exists", queue.Name))
+	}
+
+	storeErr := r.InMemoryQueueRepository.StoreQueue(queue)
+	if storeErr != nil {
+		return storeErr
+	}
+
+	if queue.IsDurable() {
+		attachErr := r.attachJournal(queue)
+		if attachErr != nil {
+			r.InMemoryQueueRepository.removeQueue(queue.Name)
+			return attachErr
+		}
+	}
+
+	return r.writeQueues()
+}
+
+func (r *FileQueueRepository) DeleteQueue(name string) (err errs.AppError) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	deleteErr := r.InMemoryQueueRepository.DeleteQueue(name)
+	if deleteErr != nil {
+		return deleteErr
+	}
+
+	journal, ok := r.journals[name]
+	if ok {
+		delete(r.journals, name)
+		journalErr := journal.Delete()
+		if journalErr != nil {
+			return journalErr
+		}
+	}
+
+	return r.writeQueues()
+}
+
+func (r *FileQueueRepository) attachJournal(queue *internal.Queue) (err errs.AppError) {
+	messages := queue.GetMessages()
+
+	journal := NewFileMessageJournal(r.journalFilePath(queue.Name), r.syncWrites)
+	resetErr := journal.Reset(messages)
+	if resetErr != nil {
+		return resetErr
+	}
+
+	queue.AttachJournal(journal)
+	r.journals[queue.Name] = journal
+
+	return nil
+}
+
+func (r *FileQueueRepository) writeQueues() (err errs.AppError) {
+	durableQueues := make([]*internal.Queue, 0)
+	for _, queue := range r.FindQueues() {
+		if queue.IsDurable() {
+			durableQueues = append(durableQueues, queue)
+		}
+	}
+
+	return writeJSONFile(r.queuesFilePath(), durableQueues)
+}
+
+func (r *FileQueueRepository) queuesFilePath() string {
+	return filepath.Join(r.dataDir, queuesFileName)
+}
+
+func (r *FileQueueRepository) journalFilePath(queueName string) string {
+	return filepath.Join(r.dataDir, queueJournalsDirName, url.PathEscape(queueName)+".log")
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/melyouz/risala/broker/internal"
+	"github.com/melyouz/risala/broker/internal/errs"
+)
+
+func newTestFileQueueRepository(t *testing.T, dataDir string) *FileQueueRepository {
+	t.Helper()
+
+	r, err := NewFileQueueRepository(dataDir, false, map[string]*internal.Queue{})
+	assert.Nil(t, err)
+
+	return r
+}
+
+func TestFileQueueRepository(t *testing.T) {
+	t.Run("Restores durable queues & their messages", func(t *testing.T) {
+		dataDir := t.TempDir()
+		r := newTestFileQueueRepository(t, dataDir)
+		assert.Nil(t, r.StoreQueue(&internal.Queue{Name: "events", Durability: internal.Durability.DURABLE}))
+		assert.Nil(t, r.StoreQueue(&internal.Queue{Name: "tmp", Durability: internal.Durability.TRANSIENT}))
+
+		events, _ := r.GetQueue("events")
+		tmp, _ := r.GetQueue("tmp")
+		for i := 1; i <= 3; i++ {
+			assert.Nil(t, events.Enqueue(&internal.Message{Id: uuid.New(), Payload: fmt.Sprintf("Message %d", i)}))
+			assert.Nil(t, tmp.Enqueue(&internal.Message{Id: uuid.New(), Payload: fmt.Sprintf("Message %d", i)}))
+		}
+		acked := events.Dequeue()
+		assert.Nil(t, events.Ack(acked.Id))
+		processing := events.Dequeue()
+
+		restored := newTestFileQueueRepository(t, dataDir)
+
+		restoredEvents, eventsErr := restored.GetQueue("events")
+		assert.Nil(t, eventsErr)
+		assert.Equal(t, internal.Durability.DURABLE, restoredEvents.Durability)
+		assert.Len(t, restoredEvents.GetMessages(), 2)
+		assert.Equal(t, processing.Id, restoredEvents.GetMessages()[0].Id)
+		assert.Equal(t, "Message 2", restoredEvents.GetMessages()[0].Payload)
+		assert.False(t, restoredEvents.GetMessages()[0].IsProcessing())
+		assert.Equal(t, "Message 3", restoredEvents.GetMessages()[1].Payload)
+
+		_, tmpErr := restored.GetQueue("tmp")
+		assert.Equal(t, errs.QueueNotFoundErrorCode, tmpErr.GetCode())
+	})
+
+	t.Run("Keeps journaling messages of restored queues", func(t *testing.T) {
+		dataDir := t.TempDir()
+		r := newTestFileQueueRepository(t, dataDir)
+		assert.Nil(t, r.StoreQueue(&internal.Queue{Name: "events", Durability: internal.Durability.DURABLE}))
+		events, _ := r.GetQueue("events")
+		assert.Nil(t, events.Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 1"}))
+
+		restored := newTestFileQueueRepository(t, dataDir)
+		restoredEvents, _ := restored.GetQueue("events")
+		assert.Nil(t, restoredEvents.Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 2"}))
+		assert.Nil(t, restoredEvents.Purge())
+		assert.Nil(t, restoredEvents.Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 3"}))
+
+		restoredAgain := newTestFileQueueRepository(t, dataDir)
+		restoredAgainEvents, _ := restoredAgain.GetQueue("events")
+		assert.Len(t, restoredAgainEvents.GetMessages(), 1)
+		assert.Equal(t, "Message 3", restoredAgainEvents.GetMessages()[0].Payload)
+	})
+
+	t.Run("Keeps journaled messages of existing queues stored again", func(t *testing.T) {
+		dataDir := t.TempDir()
+		r := newTestFileQueueRepository(t, dataDir)
+		assert.Nil(t, r.StoreQueue(&internal.Queue{Name: "events", Durability: internal.Durability.DURABLE}))
+		events, _ := r.GetQueue("events")
+		assert.Nil(t, events.Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 1"}))
+
+		storeErr := r.StoreQueue(&internal.Queue{Name: "events", Durability: internal.Durability.DURABLE})
+
+		assert.Equal(t, errs.QueueExistsErrorCode, storeErr.GetCode())
+		stored, _ := r.GetQueue("events")
+		assert.Same(t, events, stored)
+		restored := newTestFileQueueRepository(t, dataDir)
+		restoredEvents, _ := restored.GetQueue("events")
+		assert.Len(t, restoredEvents.GetMessages(), 1)
+		assert.Equal(t, "Message 1", restoredEvents.GetMessages()[0].Payload)
+	})
+
+	t.Run("Restores delivery counts & requeue delays", func(t *testing.T) {
+		dataDir := t.TempDir()
+		r := newTestFileQueueRepository(t, dataDir)
+		assert.Nil(t, r.StoreQueue(&internal.Queue{Name: "events", Durability: internal.Durability.DURABLE, MaxDeliveries: 3}))
+		events, _ := r.GetQueue("events")
+		assert.Nil(t, events.Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 1"}))
+		assert.Nil(t, events.Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 2"}))
+		first := events.Dequeue()
+		_, nackErr := events.Nack(first.Id, true, 0)
+		assert.Nil(t, nackErr)
+		first = events.Dequeue()
+		second := events.Dequeue()
+		_, nackErr = events.Nack(second.Id, true, time.Hour)
+		assert.Nil(t, nackErr)
+
+		restored := newTestFileQueueRepository(t, dataDir)
+
+		restoredEvents, _ := restored.GetQueue("events")
+		redelivered := restoredEvents.Dequeue()
+		assert.Equal(t, first.Id, redelivered.Id)
+		assert.Equal(t, 3, redelivered.DeliveryCount)
+		assert.Nil(t, restoredEvents.Dequeue())
+		messages, _ := restoredEvents.Peek(10)
+		assert.Len(t, messages, 2)
+		assert.Equal(t, second.Id, messages[1].Id)
+		assert.Equal(t, 1, messages[1].DeliveryCount)
+		assert.True(t, messages[1].Delayed)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), messages[1].AvailableAt, time.Minute)
+	})
+
+	t.Run("Removes deleted queues & their messages", func(t *testing.T) {
+		dataDir := t.TempDir()
+		r := newTestFileQueueRepository(t, dataDir)
+		assert.Nil(t, r.StoreQueue(&internal.Queue{Name: "events", Durability: internal.Durability.DURABLE}))
+		events, _ := r.GetQueue("events")
+		assert.Nil(t, events.Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 1"}))
+
+		assert.Nil(t, r.DeleteQueue("events"))
+
+		_, statErr := os.Stat(filepath.Join(dataDir, queueJournalsDirName, "events.log"))
+		assert.True(t, os.IsNotExist(statErr))
+		restored := newTestFileQueueRepository(t, dataDir)
+		assert.Empty(t, restored.FindQueues())
+	})
+
+	t.Run("Compacts journals of queues never running empty", func(t *testing.T) {
+		dataDir := t.TempDir()
+		r := newTestFileQueueRepository(t, dataDir)
+		assert.Nil(t, r.StoreQueue(&internal.Queue{Name: "events", Durability: internal.Durability.DURABLE}))
+		events, _ := r.GetQueue("events")
+		for i := 1; i <= journalCompactionThreshold; i++ {
+			assert.Nil(t, events.Enqueue(&internal.Message{Id: uuid.New(), Payload: fmt.Sprintf("Message %d", i)}))
+			assert.Nil(t, events.Ack(events.Dequeue().Id))
+			assert.Nil(t, events.Enqueue(&internal.Message{Id: uuid.New(), Payload: fmt.Sprintf("Backlog %d", i)}))
+			if i%2 == 0 {
+				assert.Nil(t, events.Ack(events.Dequeue().Id))
+			}
+		}
+
+		data, _ := os.ReadFile(filepath.Join(dataDir, queueJournalsDirName, "events.log"))
+		assert.Less(t, bytes.Count(data, []byte("\n")), 2*journalCompactionThreshold)
+		restored := newTestFileQueueRepository(t, dataDir)
+		restoredEvents, _ := restored.GetQueue("events")
+		assert.Len(t, restoredEvents.GetMessages(), journalCompactionThreshold/2)
+		assert.Equal(t, events.GetMessages()[0].Id, restoredEvents.GetMessages()[0].Id)
+	})
+
+	t.Run("Ignores partially written journal records", func(t *testing.T) {
+		dataDir := t.TempDir()
+		r := newTestFileQueueRepository(t, dataDir)
+		assert.Nil(t, r.StoreQueue(&internal.Queue{Name: "events", Durability: internal.Durability.DURABLE}))
+		events, _ := r.GetQueue("events")
+		assert.Nil(t, events.Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 1"}))
+
+		journalFile, _ := os.OpenFile(filepath.Join(dataDir, queueJournalsDirName, "events.log"), os.O_WRONLY|os.O_APPEND, 0o644)
+		_, _ = journalFile.WriteString(`{"op":"append","message":{"id":`)
+		_ = journalFile.Close()
+
+		restored := newTestFileQueueRepository(t, dataDir)
+		restoredEvents, _ := restored.GetQueue("events")
+		assert.Len(t, restoredEvents.GetMessages(), 1)
+		assert.Equal(t, "Message 1", restoredEvents.GetMessages()[0].Payload)
+	})
+
+	t.Run("Restores messages in priority order", func(t *testing.T) {
+		dataDir := t.TempDir()
+		r := newTestFileQueueRepository(t, dataDir)
+		assert.Nil(t, r.StoreQueue(&internal.Queue{Name: "events", Durability: internal.Durability.DURABLE, MaxPriority: 10}))
+		events, _ := r.GetQueue("events")
+		assert.Nil(t, events.Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 1"}))
+		assert.Nil(t, events.Enqueue(&internal.Message{Id: uuid.New(), Payload: "Message 2", Priority: 5}))
+
+		restored := newTestFileQueueRepository(t, dataDir)
+		restoredEvents, _ := restored.GetQueue("events")
+		assert.Len(t, restoredEvents.GetMessages(), 2)
+		assert.Equal(t, "Message 2", restoredEvents.GetMessages()[0].Payload)
+		assert.Equal(t, "Message 1", restoredEvents.GetMessages()[1].Payload)
+	})
+
+	t.Run("Restores delayed messages", func(t *testing.T) {
+		dataDir := t.TempDir()
+		r := newTestFileQueueRepository(t, dataDir)
+		assert.Nil(t, r.StoreQueue(&internal.Queue{Name: "events", Durability: internal.Durability.DURABLE}))
+		events, _ := r.GetQueue("events")
+		assert.Nil(t, events.Enqueue(&internal.Message{Id: uuid.New(), Payload: "Reminder", Delay: 3600}))
+
+		restored := newTestFileQueueRepository(t, dataDir)
+		restoredEvents, _ := restored.GetQueue("events")
+		assert.Nil(t, restoredEvents.Dequeue())
+		messages, _ := restoredEvents.Peek(10)
+		assert.Len(t, messages, 1)
+		assert.Equal(t, "Reminder", messages[0].Payload)
+		assert.True(t, messages[0].Delayed)
+	})
+}
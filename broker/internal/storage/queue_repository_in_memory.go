@@ -42,11 +42,12 @@ func (r *InMemoryQueueRepository) FindQueues() []*internal.Queue {
 	return result
 }
 
-func (r *InMemoryQueueRepository) StoreQueue(queue *internal.Queue) {
+func (r *InMemoryQueueRepository) StoreQueue(queue *internal.Queue) (err errs.AppError) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
 	r.QueueList[queue.Name] = queue
+	return nil
 }
 
 func (r *InMemoryQueueRepository) GetQueue(name string) (queue *internal.Queue, err errs.AppError) {
@@ -77,3 +78,10 @@ func (r *InMemoryQueueRepository) DeleteQueue(name string) (err errs.AppError) {
 	delete(r.QueueList, name)
 	return err
 }
+
+func (r *InMemoryQueueRepository) removeQueue(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.QueueList, name)
+}
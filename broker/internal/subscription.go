@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package internal
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const DefaultPrefetch = 1
+const MaxPrefetch = 1000
+
+type Subscription struct {
+	queue             *Queue
+	prefetch          int
+	visibilityTimeout time.Duration
+	// ids of the messages delivered through the subscription still in flight, removed as the queue notifies settled ones
+	unacked map[uuid.UUID]struct{}
+	settled chan uuid.UUID
+}
+
+func NewSubscription(queue *Queue, prefetch int, visibilityTimeout time.Duration) *Subscription {
+	if prefetch <= 0 {
+		prefetch = DefaultPrefetch
+	}
+
+	return &Subscription{
+		queue:             queue,
+		prefetch:          prefetch,
+		visibilityTimeout: visibilityTimeout,
+		unacked:           make(map[uuid.UUID]struct{}, prefetch),
+		settled:           make(chan uuid.UUID, prefetch),
+	}
+}
+
+func (s *Subscription) Next(ctx context.Context, wait time.Duration) (message *Message) {
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+
+	for {
+		changed := s.queue.Changed()
+		if s.hasCredit() {
+			messages := s.queue.dequeueBatch(1, s.visibilityTimeout, s.settled)
+			if len(messages) > 0 {
+				s.unacked[messages[0].Id] = struct{}{}
+				return messages[0]
+			}
+		}
+
+		due, stopDue := s.queue.untilDue()
+		select {
+		case <-ctx.Done():
+			stopDue()
+			return nil
+		case <-timeout.C:
+			stopDue()
+			return nil
+		case <-changed:
+		case <-due:
+		case messageId := <-s.settled:
+			delete(s.unacked, messageId)
+		}
+		stopDue()
+	}
+}
+
+// Close makes the messages delivered through the subscription & not settled yet visible again right away, for the other
+// consumers not to wait for their visibility timeout
+func (s *Subscription) Close() {
+	s.drainSettled()
+
+	messageIds := make([]uuid.UUID, 0, len(s.unacked))
+	for messageId := range s.unacked {
+		messageIds = append(messageIds, messageId)
+	}
+	s.unacked = map[uuid.UUID]struct{}{}
+
+	s.queue.releaseSubscribed(messageIds, s.settled)
+}
+
+func (s *Subscription) hasCredit() bool {
+	s.drainSettled()
+
+	return len(s.unacked) < s.prefetch
+}
+
+func (s *Subscription) drainSettled() {
+	for {
+		select {
+		case messageId := <-s.settled:
+			delete(s.unacked, messageId)
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscription(t *testing.T) {
+	t.Parallel()
+	t.Run("Delivers message as soon as it is enqueued", func(t *testing.T) {
+		t.Parallel()
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		subscription := NewSubscription(q, 1, 0)
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Message 1"})
+		}()
+
+		message := subscription.Next(context.Background(), 5*time.Second)
+
+		assert.NotNil(t, message)
+		assert.Equal(t, "Message 1", message.Payload)
+		assert.True(t, message.IsProcessing())
+	})
+
+	t.Run("Delivers delayed message published while waiting once it is due", func(t *testing.T) {
+		t.Parallel()
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		subscription := NewSubscription(q, 1, 0)
+
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Delayed", Delay: 1})
+		}()
+
+		start := time.Now()
+		message := subscription.Next(context.Background(), 5*time.Second)
+
+		assert.NotNil(t, message)
+		assert.Equal(t, "Delayed", message.Payload)
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+
+	t.Run("Does not deliver more unacknowledged messages than the prefetch", func(t *testing.T) {
+		t.Parallel()
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Message 1"})
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Message 2"})
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Message 3"})
+		subscription := NewSubscription(q, 2, 0)
+
+		first := subscription.Next(context.Background(), time.Second)
+		second := subscription.Next(context.Background(), time.Second)
+		third := subscription.Next(context.Background(), 50*time.Millisecond)
+
+		assert.Equal(t, "Message 1", first.Payload)
+		assert.Equal(t, "Message 2", second.Payload)
+		assert.Nil(t, third)
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_ = q.Ack(first.Id)
+		}()
+
+		third = subscription.Next(context.Background(), 5*time.Second)
+
+		assert.NotNil(t, third)
+		assert.Equal(t, "Message 3", third.Payload)
+	})
+
+	t.Run("Frees prefetch credit when message is requeued", func(t *testing.T) {
+		t.Parallel()
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Message 1"})
+		subscription := NewSubscription(q, 1, 0)
+
+		first := subscription.Next(context.Background(), time.Second)
+		_, _ = q.Nack(first.Id, true, 0)
+		redelivered := subscription.Next(context.Background(), time.Second)
+
+		assert.NotNil(t, redelivered)
+		assert.Equal(t, first.Id, redelivered.Id)
+		assert.Equal(t, 2, redelivered.DeliveryCount)
+	})
+
+	t.Run("Frees prefetch credit when visibility timeout is over", func(t *testing.T) {
+		t.Parallel()
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Message 1"})
+		subscription := NewSubscription(q, 1, 50*time.Millisecond)
+
+		first := subscription.Next(context.Background(), time.Second)
+		redelivered := subscription.Next(context.Background(), 5*time.Second)
+
+		assert.NotNil(t, redelivered)
+		assert.Equal(t, first.Id, redelivered.Id)
+		assert.Equal(t, 2, redelivered.DeliveryCount)
+	})
+
+	t.Run("Frees prefetch credit when queue is purged", func(t *testing.T) {
+		t.Parallel()
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Message 1"})
+		subscription := NewSubscription(q, 1, 0)
+
+		assert.NotNil(t, subscription.Next(context.Background(), time.Second))
+		assert.Nil(t, q.Purge())
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Message 2"})
+		message := subscription.Next(context.Background(), time.Second)
+
+		assert.NotNil(t, message)
+		assert.Equal(t, "Message 2", message.Payload)
+	})
+
+	t.Run("Releases every unsettled message on close", func(t *testing.T) {
+		t.Parallel()
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		for i := 1; i <= 4; i++ {
+			_ = q.Enqueue(&Message{Id: uuid.New(), Payload: fmt.Sprintf("Message %d", i)})
+		}
+		subscription := NewSubscription(q, 3, 0)
+		first := subscription.Next(context.Background(), time.Second)
+		second := subscription.Next(context.Background(), time.Second)
+		third := subscription.Next(context.Background(), time.Second)
+		assert.Nil(t, q.Ack(second.Id))
+
+		subscription.Close()
+
+		assert.False(t, first.IsProcessing())
+		assert.False(t, third.IsProcessing())
+		assert.Equal(t, []string{"Message 1", "Message 3", "Message 4"}, payloads(q.DequeueBatch(10, 0)))
+	})
+
+	t.Run("Leaves messages redelivered to other consumers in flight on close", func(t *testing.T) {
+		t.Parallel()
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		_ = q.Enqueue(&Message{Id: uuid.New(), Payload: "Message 1"})
+		subscription := NewSubscription(q, 1, 20*time.Millisecond)
+		first := subscription.Next(context.Background(), time.Second)
+
+		time.Sleep(50 * time.Millisecond)
+		redelivered := q.DequeueWithVisibilityTimeout(time.Minute)
+		subscription.Close()
+
+		assert.Equal(t, first.Id, redelivered.Id)
+		assert.True(t, redelivered.IsProcessing())
+		assert.Nil(t, q.Dequeue())
+	})
+
+	t.Run("Returns nil when context is done", func(t *testing.T) {
+		t.Parallel()
+		q := &Queue{Name: "testQueue", Durability: Durability.DURABLE}
+		subscription := NewSubscription(q, 1, 0)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.Nil(t, subscription.Next(ctx, time.Minute))
+	})
+}
+
+func payloads(messages []*Message) []string {
+	result := make([]string, len(messages))
+	for i, m := range messages {
+		result[i] = m.Payload
+	}
+
+	return result
+}
@@ -11,6 +11,7 @@ import (
 func NewTestExchangeWithoutBindings(name string) (queue *internal.Exchange) {
 	return &internal.Exchange{
 		Name:     name,
+		Type:     internal.ExchangeTypes.TOPIC,
 		Bindings: []*internal.Binding{},
 	}
 }
@@ -18,6 +19,15 @@ func NewTestExchangeWithoutBindings(name string) (queue *internal.Exchange) {
 func NewTestExchangeWithBindings(name string, bindings []*internal.Binding) (queue *internal.Exchange) {
 	return &internal.Exchange{
 		Name:     name,
+		Type:     internal.ExchangeTypes.TOPIC,
+		Bindings: bindings,
+	}
+}
+
+func NewTestExchange(name string, exchangeType internal.ExchangeType, bindings []*internal.Binding) (queue *internal.Exchange) {
+	return &internal.Exchange{
+		Name:     name,
+		Type:     exchangeType,
 		Bindings: bindings,
 	}
 }
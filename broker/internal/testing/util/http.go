@@ -29,6 +29,13 @@ func AssertNoContent(t *testing.T, response *httptest.ResponseRecorder) {
 	assert.Equal(t, http.StatusNoContent, response.Code)
 }
 
+func AssertBadRequest(t *testing.T, response *httptest.ResponseRecorder, expectedErrorCode string, expectedErrorMessage string) {
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	jsonResponse := JSONItemResponse(response)
+	assert.Equal(t, expectedErrorCode, jsonResponse["code"])
+	assert.Equal(t, expectedErrorMessage, jsonResponse["message"])
+}
+
 func AssertNotFound(t *testing.T, response *httptest.ResponseRecorder, expectedErrorCode string, expectedErrorMessage string) {
 	assert.Equal(t, http.StatusNotFound, response.Code)
 	jsonResponse := JSONItemResponse(response)
@@ -56,6 +63,20 @@ func AssertConflict(t *testing.T, response *httptest.ResponseRecorder, expectedE
 	assert.Equal(t, expectedErrorMessage, jsonResponse["message"])
 }
 
+func AssertUnprocessableEntity(t *testing.T, response *httptest.ResponseRecorder, expectedErrorCode string, expectedErrorMessage string) {
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	jsonResponse := JSONItemResponse(response)
+	assert.Equal(t, expectedErrorCode, jsonResponse["code"])
+	assert.Equal(t, expectedErrorMessage, jsonResponse["message"])
+}
+
+func AssertNotAcceptable(t *testing.T, response *httptest.ResponseRecorder, expectedErrorCode string, expectedErrorMessage string) {
+	assert.Equal(t, http.StatusNotAcceptable, response.Code)
+	jsonResponse := JSONItemResponse(response)
+	assert.Equal(t, expectedErrorCode, jsonResponse["code"])
+	assert.Equal(t, expectedErrorMessage, jsonResponse["message"])
+}
+
 func JSONCollectionResponse(response *httptest.ResponseRecorder) (jsonResponse []map[string]interface{}) {
 	_ = json.Unmarshal([]byte(response.Body.String()), &jsonResponse)
 
@@ -12,7 +12,6 @@ func NewTestQueueDurableWithoutMessages(name string) (queue *internal.Queue) {
 	return &internal.Queue{
 		Name:       name,
 		Durability: internal.Durability.DURABLE,
-		Messages:   []*internal.Message{},
 	}
 }
 
@@ -20,23 +19,23 @@ func NewTestQueueTransientWithoutMessages(name string) (queue *internal.Queue) {
 	return &internal.Queue{
 		Name:       name,
 		Durability: internal.Durability.TRANSIENT,
-		Messages:   []*internal.Message{},
 	}
 }
 
 func NewTestQueueTransientWithMessages(name string, messages []*internal.Message) (queue *internal.Queue) {
-	return &internal.Queue{
+	queue = &internal.Queue{
 		Name:       name,
 		Durability: internal.Durability.TRANSIENT,
-		Messages:   messages,
 	}
+	queue.RestoreMessages(messages)
+
+	return queue
 }
 
 func NewTestSystemQueueWithoutMessages(name string) (queue *internal.Queue) {
 	return &internal.Queue{
 		Name:       name,
 		Durability: internal.Durability.DURABLE,
-		Messages:   []*internal.Message{},
 		System:     true,
 	}
 }
@@ -0,0 +1,43 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package util
+
+import (
+	"strings"
+)
+
+const wordSeparator = "."
+const singleWordWildcard = "*"
+const multiWordWildcard = "#"
+
+func WildcardMatch(pattern string, value string) bool {
+	return matchWords(strings.Split(pattern, wordSeparator), strings.Split(value, wordSeparator))
+}
+
+// matchWords tells whether the pattern matches the value, going through the pattern words one at a time while keeping
+// track of the value prefixes matched so far, so that '#' wildcards never backtrack
+func matchWords(patternWords []string, valueWords []string) bool {
+	// matched[j] tells whether the pattern words gone through match the first j value words
+	matched := make([]bool, len(valueWords)+1)
+	matched[0] = true
+
+	for _, patternWord := range patternWords {
+		next := make([]bool, len(valueWords)+1)
+		if patternWord == multiWordWildcard {
+			// '#' matches zero or more words
+			next[0] = matched[0]
+			for j := 1; j <= len(valueWords); j++ {
+				next[j] = matched[j] || next[j-1]
+			}
+		} else {
+			for j := 1; j <= len(valueWords); j++ {
+				next[j] = matched[j-1] && (patternWord == singleWordWildcard || patternWord == valueWords[j-1])
+			}
+		}
+		matched = next
+	}
+
+	return matched[len(valueWords)]
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package util
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWildcardMatch(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		value    string
+		expected bool
+	}{
+		{"product.created", "product.created", true},
+		{"product.created", "product.published", false},
+		{"product.created", "product.created.v1", false},
+		{"product.*", "product.created", true},
+		{"product.*", "product", false},
+		{"product.*", "product.created.v1", false},
+		{"product.*.v1", "product.created.v1", true},
+		{"product.*.v1", "product.created.v2", false},
+		{"*.created", "product.created", true},
+		{"*.created", "created", false},
+		{"product.#", "product", true},
+		{"product.#", "product.created", true},
+		{"product.#", "product.created.v1", true},
+		{"product.#", "order.created", false},
+		{"#.v1", "product.created.v1", true},
+		{"#.v1", "v1", true},
+		{"#.v1", "product.created.v2", false},
+		{"product.#.v1", "product.v1", true},
+		{"product.#.v1", "product.created.published.v1", true},
+		{"#", "", true},
+		{"#", "product.created", true},
+		{"", "", true},
+		{"", "product", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("'%s' matching '%s' is %v", tc.pattern, tc.value, tc.expected), func(t *testing.T) {
+			assert.Equal(t, tc.expected, WildcardMatch(tc.pattern, tc.value))
+		})
+	}
+}
+
+func TestWildcardMatchWithSeveralMultiWordWildcards(t *testing.T) {
+	value := strings.Repeat("word.", 200) + "y"
+
+	start := time.Now()
+	matched := WildcardMatch("#.#.#.#.#.#.#.#.x", value)
+
+	assert.False(t, matched)
+	assert.True(t, WildcardMatch("#.#.#.#.#.#.#.#.y", value))
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -2,7 +2,7 @@
  * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
  */
 
-package util
+package validation
 
 import (
 	"reflect"
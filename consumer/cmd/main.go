@@ -5,12 +5,27 @@
 package main
 
 import (
+	"flag"
+	"log"
+
 	_ "github.com/joho/godotenv/autoload"
 
 	"github.com/melyouz/risala/consumer/internal/worker"
 )
 
 func main() {
+	transport := flag.String("transport", "http", "Consume events through TRANSPORT (http or grpc)")
+	flag.Parse()
+
+	if *transport == "grpc" {
+		eventWorker, workerErr := worker.NewGrpcEventWorker()
+		if workerErr != nil {
+			log.Fatal(workerErr)
+		}
+		eventWorker.Start()
+		return
+	}
+
 	eventWorker := worker.NewEventWorker()
 	eventWorker.Start()
 }
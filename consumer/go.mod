@@ -2,4 +2,19 @@ module github.com/melyouz/risala/consumer
 
 go 1.23
 
-require github.com/joho/godotenv v1.5.1 // indirect
+require (
+	github.com/google/uuid v1.6.0
+	github.com/joho/godotenv v1.5.1
+	github.com/melyouz/risala/broker v0.0.0-00010101000000-000000000000
+	google.golang.org/grpc v1.67.1
+)
+
+require (
+	golang.org/x/net v0.31.0 // indirect
+	golang.org/x/sys v0.27.0 // indirect
+	golang.org/x/text v0.20.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240814211410-ddb44dafa142 // indirect
+	google.golang.org/protobuf v1.36.12 // indirect
+)
+
+replace github.com/melyouz/risala/broker => ../broker
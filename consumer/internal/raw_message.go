@@ -5,10 +5,17 @@
 package internal
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type RawMessage struct {
-	Id      uuid.UUID `json:"id"`
-	Payload string    `json:"payload"`
+	Id            uuid.UUID         `json:"id"`
+	Payload       string            `json:"payload"`
+	RoutingKey    string            `json:"routingKey"`
+	Headers       map[string]string `json:"headers"`
+	ContentType   string            `json:"contentType"`
+	CorrelationId string            `json:"correlationId"`
+	Timestamp     time.Time         `json:"timestamp"`
 }
@@ -5,11 +5,14 @@
 package worker
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -18,6 +21,11 @@ import (
 	"github.com/melyouz/risala/consumer/internal/util"
 )
 
+const retryDelay = 10 * time.Second
+const reconnectDelay = time.Second
+const prefetch = 10
+const maxEventSize = 1024 * 1024
+
 type EventWorker struct {
 }
 
@@ -28,13 +36,14 @@ func NewEventWorker() *EventWorker {
 func (w *EventWorker) Start() {
 	for {
 		consumeMessages()
+		time.Sleep(reconnectDelay)
 	}
 }
 
 func consumeMessages() {
 	eventsQueueEndpoint := util.GetEnvVarStringRequired("QUEUE_EVENTS_ENDPOINT")
-	messageConsumeEndpoint := fmt.Sprintf("%s/messages/get", eventsQueueEndpoint)
-	response, connectionErr := http.Post(messageConsumeEndpoint, "application/json", nil)
+	messageSubscribeEndpoint := fmt.Sprintf("%s/messages/subscribe?prefetch=%d", eventsQueueEndpoint, prefetch)
+	response, connectionErr := http.Get(messageSubscribeEndpoint)
 	if connectionErr != nil {
 		log.Println("[Worker] Error connecting to Broker:", connectionErr)
 		return
@@ -45,51 +54,97 @@ func consumeMessages() {
 		}
 	}()
 
-	if response.StatusCode == http.StatusNoContent {
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(response.Body)
+		log.Printf("[Worker] Error subscribing to messages: %s %s %s", messageSubscribeEndpoint, response.Status, string(body))
 		return
 	}
 
-	body, readErr := io.ReadAll(response.Body)
-	if readErr != nil {
-		log.Println("[Worker] Error reading response body:", readErr)
-		return
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+	for scanner.Scan() {
+		data, found := strings.CutPrefix(scanner.Text(), "data: ")
+		if !found {
+			continue
+		}
+
+		var rawMessage internal.RawMessage
+		if rawMessageDecodeErr := json.Unmarshal([]byte(data), &rawMessage); rawMessageDecodeErr != nil {
+			log.Println("[Worker] Error decoding message event:", rawMessageDecodeErr)
+			if messageId, found := messageIdFromData(data); found {
+				httpAcknowledger{}.nack(messageId, false, 0)
+			}
+			continue
+		}
+
+		event, deserializeErr := eventFromMessage(rawMessage)
+		if deserializeErr != nil {
+			log.Println("[Worker] Error deserializing message payload:", deserializeErr)
+			httpAcknowledger{}.nack(rawMessage.Id, false, 0)
+			continue
+		}
+
+		processEvent(rawMessage.Id, event, httpAcknowledger{})
 	}
 
-	var rawMessage internal.RawMessage
-	if rawMessageDecodeErr := json.Unmarshal(body, &rawMessage); rawMessageDecodeErr != nil {
-		log.Println("[Worker] Error decoding response body:", rawMessageDecodeErr)
-		return
+	if scanErr := scanner.Err(); scanErr != nil {
+		log.Println("[Worker] Error reading message events:", scanErr)
+	}
+}
+
+// messageIdFromData extracts the id of a message event failing to be decoded, so the message can still be rejected
+func messageIdFromData(data string) (messageId uuid.UUID, found bool) {
+	var envelope struct {
+		Id uuid.UUID `json:"id"`
+	}
+	if decodeErr := json.Unmarshal([]byte(data), &envelope); decodeErr != nil || envelope.Id == uuid.Nil {
+		return uuid.Nil, false
 	}
 
-	var event internal.Event
-	if deserializeErr := json.Unmarshal([]byte(rawMessage.Payload), &event); deserializeErr != nil {
-		log.Println("[Worker] Error deserializing message payload:", deserializeErr)
-		return
+	return envelope.Id, true
+}
+
+func eventFromMessage(rawMessage internal.RawMessage) (event internal.Event, err error) {
+	if err = json.Unmarshal([]byte(rawMessage.Payload), &event.Data); err != nil {
+		return event, err
+	}
+
+	event.Id = rawMessage.Id
+	if correlationId, parseErr := uuid.Parse(rawMessage.CorrelationId); parseErr == nil {
+		event.Id = correlationId
 	}
 
-	processEvent(rawMessage.Id, event)
+	event.EventType = rawMessage.Headers["type"]
+	if event.EventType == "" {
+		event.EventType = rawMessage.RoutingKey
+	}
+
+	event.Timestamp = rawMessage.Timestamp.Unix()
+
+	return event, nil
 }
 
-func processEvent(messageId uuid.UUID, event internal.Event) {
+func processEvent(messageId uuid.UUID, event internal.Event, acknowledger acknowledger) {
 	fmt.Println("")
 	log.Println("[Worker] Event process INIT:", event)
 
-	eventHandled := handleEvent(event)
+	eventActionFound, eventHandled := handleEvent(event)
 
 	if eventHandled {
 		log.Println("[Worker] Event handled:", event.EventType)
-		sendAcknowledgement(messageId, "ack")
+		acknowledger.ack(messageId)
+	} else if eventActionFound {
+		log.Println("[Worker] Event not handled, retrying later:", event.EventType)
+		acknowledger.nack(messageId, true, retryDelay)
 	} else {
 		log.Println("[Worker] Event not handled:", event.EventType)
-		sendAcknowledgement(messageId, "nack")
+		acknowledger.nack(messageId, false, 0)
 	}
 
 	log.Println("[Worker] Event process END:", event)
 }
 
-func handleEvent(event internal.Event) (eventProcessed bool) {
-	var eventActionFound bool
-
+func handleEvent(event internal.Event) (eventActionFound bool, eventProcessed bool) {
 	for _, eventAction := range action.Actions {
 		if util.WildcardMatch(eventAction.SupportedType(), event.EventType) {
 			eventActionFound = true
@@ -106,12 +161,31 @@ func handleEvent(event internal.Event) (eventProcessed bool) {
 		log.Println("[Worker] Event action not found for event type:", event.EventType)
 	}
 
-	return eventProcessed
+	return eventActionFound, eventProcessed
+}
+
+type acknowledger interface {
+	ack(messageId uuid.UUID)
+	nack(messageId uuid.UUID, requeue bool, delay time.Duration)
+}
+
+type httpAcknowledger struct{}
+
+func (a httpAcknowledger) ack(messageId uuid.UUID) {
+	sendAcknowledgement(messageId, "ack", "")
+}
+
+func (a httpAcknowledger) nack(messageId uuid.UUID, requeue bool, delay time.Duration) {
+	query := ""
+	if requeue {
+		query = fmt.Sprintf("requeue=true&delay=%s", delay)
+	}
+	sendAcknowledgement(messageId, "nack", query)
 }
 
-func sendAcknowledgement(messageId uuid.UUID, ackType string) {
+func sendAcknowledgement(messageId uuid.UUID, ackType string, query string) {
 	eventsQueueEndpoint := util.GetEnvVarStringRequired("QUEUE_EVENTS_ENDPOINT")
-	messageEndpoint := fmt.Sprintf("%s/messages/%s/%s", eventsQueueEndpoint, messageId.String(), ackType)
+	messageEndpoint := fmt.Sprintf("%s/messages/%s/%s?%s", eventsQueueEndpoint, messageId.String(), ackType, query)
 	response, connectionErr := http.Post(messageEndpoint, "application/json", nil)
 	if connectionErr != nil {
 		log.Println("[Worker] An error occurred while connecting to Broker:", connectionErr)
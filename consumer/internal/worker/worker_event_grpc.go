@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/melyouz/risala/broker/pkg/pb"
+	"github.com/melyouz/risala/consumer/internal"
+	"github.com/melyouz/risala/consumer/internal/util"
+)
+
+type GRPCEventWorker struct {
+	client pb.BrokerClient
+	queue  string
+}
+
+func NewGrpcEventWorker() (*GRPCEventWorker, error) {
+	brokerAddr := util.GetEnvVarStringRequired("BROKER_GRPC_ADDR")
+	conn, connectionErr := grpc.NewClient(brokerAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if connectionErr != nil {
+		return nil, connectionErr
+	}
+
+	return &GRPCEventWorker{
+		client: pb.NewBrokerClient(conn),
+		queue:  util.GetEnvVarStringRequired("QUEUE_EVENTS_NAME"),
+	}, nil
+}
+
+func (w *GRPCEventWorker) Start() {
+	for {
+		if consumeErr := w.consumeMessages(); consumeErr != nil {
+			log.Println("[Worker] Error consuming messages:", consumeErr)
+		}
+		time.Sleep(reconnectDelay)
+	}
+}
+
+func (w *GRPCEventWorker) consumeMessages() error {
+	stream, consumeErr := w.client.Consume(context.Background(), &pb.ConsumeRequest{Queue: w.queue, Prefetch: prefetch})
+	if consumeErr != nil {
+		return consumeErr
+	}
+
+	for {
+		message, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			return nil
+		}
+		if recvErr != nil {
+			return recvErr
+		}
+
+		messageId, uuidErr := uuid.Parse(message.GetId())
+		if uuidErr != nil {
+			log.Println("[Worker] Error parsing message id:", uuidErr)
+			continue
+		}
+
+		event, deserializeErr := eventFromMessage(internal.RawMessage{
+			Id:            messageId,
+			Payload:       string(message.GetPayload()),
+			RoutingKey:    message.GetRoutingKey(),
+			Headers:       message.GetHeaders(),
+			ContentType:   message.GetContentType(),
+			CorrelationId: message.GetCorrelationId(),
+			Timestamp:     message.GetTimestamp().AsTime(),
+		})
+		acknowledger := grpcAcknowledger{client: w.client, queue: w.queue}
+		if deserializeErr != nil {
+			log.Println("[Worker] Error deserializing message payload:", deserializeErr)
+			acknowledger.nack(messageId, false, 0)
+			continue
+		}
+
+		processEvent(messageId, event, acknowledger)
+	}
+}
+
+type grpcAcknowledger struct {
+	client pb.BrokerClient
+	queue  string
+}
+
+func (a grpcAcknowledger) ack(messageId uuid.UUID) {
+	_, ackErr := a.client.Ack(context.Background(), &pb.AckRequest{Queue: a.queue, MessageId: messageId.String()})
+	if ackErr != nil {
+		log.Printf("[Worker] Error ack-ing message: %s %s", messageId, ackErr)
+		return
+	}
+
+	log.Printf("[Worker] Message %s ack-ed", messageId)
+}
+
+func (a grpcAcknowledger) nack(messageId uuid.UUID, requeue bool, delay time.Duration) {
+	_, nackErr := a.client.Nack(context.Background(), &pb.NackRequest{
+		Queue:        a.queue,
+		MessageId:    messageId.String(),
+		Requeue:      requeue,
+		DelaySeconds: int64(delay / time.Second),
+	})
+	if nackErr != nil {
+		log.Printf("[Worker] Error nack-ing message: %s %s", messageId, nackErr)
+		return
+	}
+
+	log.Printf("[Worker] Message %s nack-ed", messageId)
+}
@@ -7,6 +7,7 @@ package main
 import (
 	"flag"
 	"log"
+	"sync/atomic"
 	"time"
 
 	_ "github.com/joho/godotenv/autoload"
@@ -14,6 +15,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/melyouz/risala/producer/internal"
+	"github.com/melyouz/risala/producer/internal/errs"
 	"github.com/melyouz/risala/producer/internal/sender"
 )
 
@@ -22,12 +24,38 @@ func main() {
 	log.Println("Sending events...")
 
 	eventsCount := flag.Int("events-count", 1000, "Send EVENTS-COUNT events")
+	transport := flag.String("transport", "http", "Send events through TRANSPORT (http or grpc)")
+	batchSize := flag.Int("batch-size", 1, "Publish events in batches of up to BATCH-SIZE events (http transport only)")
+	flushInterval := flag.Duration("flush-interval", time.Second, "Publish pending events at least every FLUSH-INTERVAL when batching")
 	flag.Parse()
 
 	log.Printf("eventsCount: %d", *eventsCount)
+	log.Printf("transport: %s", *transport)
+	log.Printf("batchSize: %d", *batchSize)
+
+	var sentCount, failedCount atomic.Int64
+	reportSent := func(event internal.Event, err errs.AppError) {
+		if err != nil {
+			failedCount.Add(1)
+			log.Printf("failed to send event %s: %v", event.Id, err)
+			return
+		}
+		sentCount.Add(1)
+	}
+
+	var eventSender sender.EventSender = sender.NewHttpEventSender()
+	batched := false
+	if *transport == "grpc" {
+		grpcEventSender, senderErr := sender.NewGrpcEventSender()
+		if senderErr != nil {
+			log.Fatal(senderErr)
+		}
+		eventSender = grpcEventSender
+	} else if *batchSize > 1 {
+		eventSender = sender.NewHttpBatchEventSender(*batchSize, *flushInterval, reportSent)
+		batched = true
+	}
 
-	successCount := 0
-	eventSender := sender.NewHttpEventSender()
 	for i := 0; i < *eventsCount; i++ {
 		event := internal.Event{
 			Id:        uuid.New(),
@@ -40,15 +68,21 @@ func main() {
 		}
 
 		err := eventSender.Send(event)
-		if err != nil {
-			log.Printf("failed to send event: %v", err)
-		} else {
-			successCount++
+		// batched events are reported once their batch is published
+		if err != nil || !batched {
+			reportSent(event, err)
 		}
 	}
 
-	if successCount > 0 {
-		log.Printf("Successfully sent %d events", successCount)
+	if closeErr := eventSender.Close(); closeErr != nil {
+		log.Printf("failed to send pending events: %v", closeErr)
+	}
+
+	if sentCount.Load() > 0 {
+		log.Printf("Successfully sent %d events", sentCount.Load())
+	}
+	if failedCount.Load() > 0 {
+		log.Printf("Failed to send %d events", failedCount.Load())
 	}
 
 	log.Printf("end")
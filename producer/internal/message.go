@@ -5,5 +5,11 @@
 package internal
 
 type Message struct {
-	Payload string `json:"payload"`
+	Payload         string            `json:"payload"`
+	RoutingKey      string            `json:"routingKey"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	ContentType     string            `json:"contentType,omitempty"`
+	CorrelationId   string            `json:"correlationId,omitempty"`
+	DeduplicationId string            `json:"deduplicationId,omitempty"`
+	GroupId         string            `json:"groupId,omitempty"`
 }
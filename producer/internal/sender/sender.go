@@ -5,10 +5,41 @@
 package sender
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/melyouz/risala/producer/internal"
 	"github.com/melyouz/risala/producer/internal/errs"
 )
 
 type EventSender interface {
 	Send(event internal.Event) errs.AppError
+	Close() errs.AppError
+}
+
+// SentHandler is given the outcome of an event once the Broker handled it, err being nil when the event was published
+type SentHandler func(event internal.Event, err errs.AppError)
+
+func messageFromEvent(event internal.Event) (internal.Message, errs.AppError) {
+	encodedEventData, eventEncodeErr := json.Marshal(event.Data)
+	if eventEncodeErr != nil {
+		return internal.Message{}, errs.NewEncodeError(fmt.Sprintf("Error encoding event: %s", eventEncodeErr))
+	}
+
+	return internal.Message{
+		Payload:         string(encodedEventData),
+		RoutingKey:      event.EventType,
+		Headers:         map[string]string{"type": event.EventType},
+		ContentType:     "application/json",
+		CorrelationId:   event.Id.String(),
+		DeduplicationId: event.Id.String(),
+		GroupId:         groupIdFromEvent(event),
+	}, nil
+}
+
+// groupIdFromEvent groups the events of the same product, so that they are processed one at a time & in order
+func groupIdFromEvent(event internal.Event) string {
+	productId, _ := event.Data["productId"].(string)
+
+	return productId
 }
@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package sender
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/melyouz/risala/broker/pkg/pb"
+	"github.com/melyouz/risala/producer/internal"
+	"github.com/melyouz/risala/producer/internal/errs"
+	"github.com/melyouz/risala/producer/internal/util"
+)
+
+type GRPCEventSender struct {
+	conn     *grpc.ClientConn
+	client   pb.BrokerClient
+	exchange string
+}
+
+func NewGrpcEventSender() (*GRPCEventSender, errs.AppError) {
+	brokerAddr := util.GetEnvVarStringRequired("BROKER_GRPC_ADDR")
+	conn, connectionErr := grpc.NewClient(brokerAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if connectionErr != nil {
+		return nil, errs.NewConnectionError(fmt.Sprintf("Error connecting to Broker: %s", connectionErr))
+	}
+
+	return &GRPCEventSender{
+		conn:     conn,
+		client:   pb.NewBrokerClient(conn),
+		exchange: util.GetEnvVarStringRequired("EXCHANGE_INTERNAL_NAME"),
+	}, nil
+}
+
+func (s *GRPCEventSender) Send(event internal.Event) errs.AppError {
+	message, messageErr := messageFromEvent(event)
+	if messageErr != nil {
+		return messageErr
+	}
+
+	_, publishErr := s.client.PublishToExchange(context.Background(), &pb.PublishToExchangeRequest{
+		Exchange: s.exchange,
+		Message: &pb.Message{
+			Payload:         []byte(message.Payload),
+			RoutingKey:      message.RoutingKey,
+			Headers:         message.Headers,
+			ContentType:     message.ContentType,
+			CorrelationId:   message.CorrelationId,
+			DeduplicationId: message.DeduplicationId,
+			GroupId:         message.GroupId,
+		},
+		Mandatory: true,
+	})
+	if publishErr != nil {
+		return errs.NewApiError(status.Convert(publishErr).Message())
+	}
+
+	return nil
+}
+
+func (s *GRPCEventSender) Close() errs.AppError {
+	if closeErr := s.conn.Close(); closeErr != nil {
+		return errs.NewConnectionError(fmt.Sprintf("Error closing connection to Broker: %s", closeErr))
+	}
+
+	return nil
+}
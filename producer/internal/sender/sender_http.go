@@ -25,14 +25,13 @@ func NewHttpEventSender() *HTTPEventSender {
 
 func (s *HTTPEventSender) Send(event internal.Event) errs.AppError {
 	internalExchangeEndpoint := util.GetEnvVarStringRequired("EXCHANGE_INTERNAL_ENDPOINT")
-	messagePublishEndpoint := fmt.Sprintf("%s/messages/publish", internalExchangeEndpoint)
+	messagePublishEndpoint := fmt.Sprintf("%s/messages/publish?mandatory=true", internalExchangeEndpoint)
 
-	encodedEvent, eventEncodeErr := json.Marshal(event)
-	if eventEncodeErr != nil {
-		return errs.NewEncodeError(fmt.Sprintf("Error encoding event: %s", eventEncodeErr))
+	message, messageErr := messageFromEvent(event)
+	if messageErr != nil {
+		return messageErr
 	}
 
-	message := internal.Message{Payload: string(encodedEvent)}
 	encodedMessage, messageEncodeErr := json.Marshal(message)
 	if messageEncodeErr != nil {
 		return errs.NewEncodeError(fmt.Sprintf("Error encoding message: %s", messageEncodeErr))
@@ -48,7 +47,8 @@ func (s *HTTPEventSender) Send(event internal.Event) errs.AppError {
 		}
 	}()
 
-	if response.StatusCode != http.StatusOK {
+	// 200 OK: the event was already published (e.g. on a retry) & dropped as a duplicate
+	if response.StatusCode != http.StatusCreated && response.StatusCode != http.StatusOK {
 		body, readErr := io.ReadAll(response.Body)
 		if readErr != nil {
 			return errs.NewReadError(fmt.Sprintf("Error reading response body: %s", readErr))
@@ -64,3 +64,7 @@ func (s *HTTPEventSender) Send(event internal.Event) errs.AppError {
 
 	return nil
 }
+
+func (s *HTTPEventSender) Close() errs.AppError {
+	return nil
+}
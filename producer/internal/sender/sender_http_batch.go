@@ -0,0 +1,211 @@
+/*
+ * Copyright (c) 2024 Mohammadi El Youzghi and contributors.
+ */
+
+package sender
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/melyouz/risala/producer/internal"
+	"github.com/melyouz/risala/producer/internal/errs"
+	"github.com/melyouz/risala/producer/internal/util"
+)
+
+const MaxBatchSize = 100
+
+type publishResult struct {
+	MessageId string      `json:"messageId,omitempty"`
+	Success   bool        `json:"success"`
+	Error     *errs.Error `json:"error,omitempty"`
+}
+
+// HTTPBatchEventSender buffers events to publish them in batches: Send only fails when an event can't be buffered, the
+// outcome of every buffered event being reported to onSent once its batch is published
+type HTTPBatchEventSender struct {
+	endpoint  string
+	batchSize int
+	onSent    SentHandler
+	mu        sync.Mutex
+	flushMu   sync.Mutex
+	events    []internal.Event
+	messages  []internal.Message
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+func NewHttpBatchEventSender(batchSize int, flushInterval time.Duration, onSent SentHandler) *HTTPBatchEventSender {
+	internalExchangeEndpoint := util.GetEnvVarStringRequired("EXCHANGE_INTERNAL_ENDPOINT")
+	batchSize = max(1, min(batchSize, MaxBatchSize))
+
+	s := &HTTPBatchEventSender{
+		endpoint:  fmt.Sprintf("%s/messages/publish-batch?mandatory=true", internalExchangeEndpoint),
+		batchSize: batchSize,
+		onSent:    onSent,
+		events:    make([]internal.Event, 0, batchSize),
+		messages:  make([]internal.Message, 0, batchSize),
+		done:      make(chan struct{}),
+	}
+
+	if flushInterval > 0 {
+		s.wg.Add(1)
+		go s.flushPeriodically(flushInterval)
+	}
+
+	return s
+}
+
+func (s *HTTPBatchEventSender) Send(event internal.Event) errs.AppError {
+	message, messageErr := messageFromEvent(event)
+	if messageErr != nil {
+		return messageErr
+	}
+
+	s.mu.Lock()
+	s.events = append(s.events, event)
+	s.messages = append(s.messages, message)
+	full := len(s.messages) >= s.batchSize
+	s.mu.Unlock()
+
+	if full {
+		_ = s.flushNext()
+	}
+
+	return nil
+}
+
+// Close publishes the pending events, returning the error of their batch if any
+func (s *HTTPBatchEventSender) Close() errs.AppError {
+	close(s.done)
+	s.wg.Wait()
+
+	var err errs.AppError
+	for {
+		s.mu.Lock()
+		pending := len(s.messages)
+		s.mu.Unlock()
+		if pending == 0 {
+			return err
+		}
+
+		if flushErr := s.flushNext(); flushErr != nil {
+			err = flushErr
+		}
+	}
+}
+
+func (s *HTTPBatchEventSender) flushPeriodically(flushInterval time.Duration) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			_ = s.flushNext()
+		}
+	}
+}
+
+// flushNext publishes the oldest pending events, up to the batch size: flushes are serialized so that batches reach the
+// Broker one at a time & in order, keeping the events of a group in order
+func (s *HTTPBatchEventSender) flushNext() errs.AppError {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	events, messages := s.takeBatch()
+	s.mu.Unlock()
+
+	return s.flush(events, messages)
+}
+
+// takeBatch takes the oldest pending events, up to the batch size: events sent while a batch is being published are
+// left for the next one
+func (s *HTTPBatchEventSender) takeBatch() ([]internal.Event, []internal.Message) {
+	size := min(len(s.messages), s.batchSize)
+	events, messages := s.events[:size:size], s.messages[:size:size]
+	s.events = append(make([]internal.Event, 0, s.batchSize), s.events[size:]...)
+	s.messages = append(make([]internal.Message, 0, s.batchSize), s.messages[size:]...)
+
+	return events, messages
+}
+
+// flush publishes the batch, reporting the outcome of each of its events
+func (s *HTTPBatchEventSender) flush(events []internal.Event, messages []internal.Message) errs.AppError {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	results, publishErr := s.publish(messages)
+	failedCount := 0
+	for i, event := range events {
+		var err errs.AppError = publishErr
+		if err == nil && !results[i].Success {
+			err = errs.NewApiError(fmt.Sprintf("Message could not be published: %v", results[i].Error))
+		}
+		if err != nil {
+			failedCount++
+		}
+		s.onSent(event, err)
+	}
+
+	if publishErr != nil {
+		return publishErr
+	}
+	if failedCount > 0 {
+		return errs.NewApiError(fmt.Sprintf("%d of %d messages could not be published", failedCount, len(messages)))
+	}
+
+	return nil
+}
+
+func (s *HTTPBatchEventSender) publish(messages []internal.Message) (results []publishResult, err errs.AppError) {
+	encodedMessages, messagesEncodeErr := json.Marshal(messages)
+	if messagesEncodeErr != nil {
+		return nil, errs.NewEncodeError(fmt.Sprintf("Error encoding messages: %s", messagesEncodeErr))
+	}
+
+	response, connectionErr := http.Post(s.endpoint, "application/json", bytes.NewBuffer(encodedMessages))
+	if connectionErr != nil {
+		return nil, errs.NewConnectionError(fmt.Sprintf("Error connecting to Broker: %s", connectionErr))
+	}
+	defer func() {
+		if closeErr := response.Body.Close(); closeErr != nil {
+			log.Println("[Sender] Error closing response body:", closeErr)
+		}
+	}()
+
+	body, readErr := io.ReadAll(response.Body)
+	if readErr != nil {
+		return nil, errs.NewReadError(fmt.Sprintf("Error reading response body: %s", readErr))
+	}
+
+	if response.StatusCode != http.StatusOK {
+		var apiErr errs.Error
+		if decodeErr := json.Unmarshal(body, &apiErr); decodeErr != nil {
+			return nil, errs.NewDecodeError(fmt.Sprintf("Error decoding response body: %s", decodeErr))
+		}
+
+		return nil, &apiErr
+	}
+
+	if decodeErr := json.Unmarshal(body, &results); decodeErr != nil {
+		return nil, errs.NewDecodeError(fmt.Sprintf("Error decoding response body: %s", decodeErr))
+	}
+	if len(results) != len(messages) {
+		return nil, errs.NewDecodeError(fmt.Sprintf("Expected %d results, got %d", len(messages), len(results)))
+	}
+
+	return results, nil
+}